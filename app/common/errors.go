@@ -18,6 +18,10 @@ const (
 	ErrCodeCommandFailed   = 1006
 	ErrCodeDatabaseError   = 1007
 	ErrCodePermissionDenied = 1008
+	ErrCodeFileError       = 1009
+	ErrCodeUnauthorized    = 1010
+	ErrCodeTooManyRequests = 1011
+	ErrCodeNotReady        = 1012
 )
 
 // BusinessError 业务错误
@@ -48,6 +52,10 @@ var (
 	ErrCommandFailed   = NewBusinessError(ErrCodeCommandFailed, "命令执行失败")
 	ErrDatabaseError   = NewBusinessError(ErrCodeDatabaseError, "数据库操作失败")
 	ErrPermissionDenied = NewBusinessError(ErrCodePermissionDenied, "权限不足")
+	ErrFileError       = NewBusinessError(ErrCodeFileError, "文件操作失败")
+	ErrUnauthorized    = NewBusinessError(ErrCodeUnauthorized, "未登录或登录已过期")
+	ErrTooManyRequests = NewBusinessError(ErrCodeTooManyRequests, "请求过于频繁，请稍后重试")
+	ErrNotReady        = NewBusinessError(ErrCodeNotReady, "服务未就绪")
 )
 
 // ErrorResponse 统一错误响应处理
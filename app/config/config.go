@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -30,15 +33,27 @@ type AppConfig struct {
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         string        `mapstructure:"port"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	Host            string        `mapstructure:"host"`
+	Port            string        `mapstructure:"port"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // 退出时等待进行中的请求和操作完成的最长时间
 }
 
+// 数据库驱动
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// DefaultSQLitePath SQLite 数据库文件的默认路径
+const DefaultSQLitePath = "data/go_service.db"
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	Driver          string        `mapstructure:"driver"` // mysql(默认) 或 sqlite
+	Path            string        `mapstructure:"path"`   // SQLite 数据库文件路径，默认 data/go_service.db
 	Host            string        `mapstructure:"host"`
 	Port            int           `mapstructure:"port"`
 	User            string        `mapstructure:"user"`
@@ -70,32 +85,59 @@ type LogConfig struct {
 	MaxBackups int    `mapstructure:"max_backups"`
 	MaxAge     int    `mapstructure:"max_age"`
 	Compress   bool   `mapstructure:"compress"`
+	ServiceDir string `mapstructure:"service_dir"` // 服务输出日志目录，每个服务一个文件
 }
 
 // MonitorConfig 监控配置
 type MonitorConfig struct {
-	Enabled         bool          `mapstructure:"enabled"`
-	HealthCheckPath string        `mapstructure:"health_check_path"`
-	MetricsPath     string        `mapstructure:"metrics_path"`
-	CheckInterval   time.Duration `mapstructure:"check_interval"`
-	Timeout         time.Duration `mapstructure:"timeout"`
-	AlertWebhook    string        `mapstructure:"alert_webhook"`
-	RetentionDays   int           `mapstructure:"retention_days"`
+	Enabled             bool          `mapstructure:"enabled"`
+	HealthCheckPath     string        `mapstructure:"health_check_path"`
+	MetricsPath         string        `mapstructure:"metrics_path"`
+	CheckInterval       time.Duration `mapstructure:"check_interval"`
+	Timeout             time.Duration `mapstructure:"timeout"`
+	AlertWebhook        string        `mapstructure:"alert_webhook"`
+	AlertFormat         string        `mapstructure:"alert_format"`          // 告警消息格式: auto, json, dingtalk, wecom, feishu, slack
+	AlertSecret         string        `mapstructure:"alert_secret"`          // 钉钉、飞书机器人的加签密钥
+	AlertRetries        int           `mapstructure:"alert_retries"`         // 发送失败后的重试次数
+	AlertBackoff        time.Duration `mapstructure:"alert_backoff"`         // 首次重试的等待时间，之后每次翻倍
+	AlertSilence        time.Duration `mapstructure:"alert_silence"`         // 同一服务同类告警的最小间隔
+	RetentionDays       int           `mapstructure:"retention_days"`        // 操作日志保留天数，0 表示不清理
+	RetentionTime       string        `mapstructure:"retention_time"`        // 每天执行日志清理的时间，如 03:00
+	RetentionKeepLast   int           `mapstructure:"retention_keep_last"`   // 每个服务至少保留的最近日志条数
+	RetentionArchiveDir string        `mapstructure:"retention_archive_dir"` // 删除前归档为 jsonl.gz 的目录，空表示不归档
+	WatchdogInterval    time.Duration `mapstructure:"watchdog_interval"`     // 自动重启守护检测间隔
+	UnhealthyThreshold  int           `mapstructure:"unhealthy_threshold"`   // 连续健康检查失败多少次触发自动重启
 }
 
 // SecurityConfig 安全配置
 type SecurityConfig struct {
-	EnableAuth       bool          `mapstructure:"enable_auth"`
-	JWTSecret        string        `mapstructure:"jwt_secret"`
-	TokenExpiry      time.Duration `mapstructure:"token_expiry"`
-	RateLimitEnabled bool          `mapstructure:"rate_limit_enabled"`
-	RateLimitRPS     int           `mapstructure:"rate_limit_rps"`
-	AllowedIPs       []string      `mapstructure:"allowed_ips"`
-	TLSEnabled       bool          `mapstructure:"tls_enabled"`
-	CertFile         string        `mapstructure:"cert_file"`
-	KeyFile          string        `mapstructure:"key_file"`
+	EnableAuth        bool          `mapstructure:"enable_auth"`
+	JWTSecret         string        `mapstructure:"jwt_secret"`
+	TokenExpiry       time.Duration `mapstructure:"token_expiry"`
+	RefreshExpiry     time.Duration `mapstructure:"refresh_expiry"` // 刷新令牌有效期
+	AdminUsername     string        `mapstructure:"admin_username"` // 用户表为空时创建的初始管理员
+	AdminPassword     string        `mapstructure:"admin_password"` // 为空时随机生成并打印到日志
+	RateLimitEnabled  bool          `mapstructure:"rate_limit_enabled"`
+	RateLimitRPS      int           `mapstructure:"rate_limit_rps"`
+	RateLimitBurst    int           `mapstructure:"rate_limit_burst"`     // 突发请求数，不大于 RateLimitRPS 时取 RateLimitRPS
+	CmdRateLimitRPS   float64       `mapstructure:"cmd_rate_limit_rps"`   // 启动、停止等命令接口的每秒请求数
+	CmdRateLimitBurst int           `mapstructure:"cmd_rate_limit_burst"` // 命令接口的突发请求数
+	AllowedIPs        []string      `mapstructure:"allowed_ips"`          // 允许访问的IP或CIDR，空表示允许所有
+	TrustedProxies    []string      `mapstructure:"trusted_proxies"`      // 可信代理的IP或CIDR，来自可信代理的请求按 X-Forwarded-For 识别客户端
+	TLSEnabled        bool          `mapstructure:"tls_enabled"`
+	CertFile          string        `mapstructure:"cert_file"`
+	KeyFile           string        `mapstructure:"key_file"`
+	ClientCAFile      string        `mapstructure:"client_ca_file"` // 校验客户端证书的CA
+	ClientAuth        string        `mapstructure:"client_auth"`    // 客户端证书: none, optional, require
 }
 
+// 客户端证书认证方式
+const (
+	ClientAuthNone     = "none"     // 不要求客户端证书
+	ClientAuthOptional = "optional" // 客户端提供证书时校验
+	ClientAuthRequire  = "require"  // 必须提供有效的客户端证书
+)
+
 var (
 	GlobalConfig *Config
 	configPath   string
@@ -144,26 +186,31 @@ func InitConfig(configFile string) error {
 	viper.WatchConfig()
 	viper.OnConfigChange(func(e fsnotify.Event) {
 		fmt.Printf("配置文件发生变化: %s\n", e.Name)
-		oldConfig := GlobalConfig
-		newConfig := &Config{}
-		if err := viper.Unmarshal(newConfig); err != nil {
-			fmt.Printf("重新加载配置失败: %v\n", err)
-			return
+		if err := applyConfig(); err != nil {
+			fmt.Printf("%v\n", err)
 		}
+	})
 
-		// 验证新配置
-		if err := validateConfig(newConfig); err != nil {
-			fmt.Printf("新配置验证失败: %v\n", err)
-			return
-		}
+	return nil
+}
+
+// applyConfig 解析并验证viper中的配置，成功后替换全局配置并触发变更回调，失败时保留原配置
+func applyConfig() error {
+	oldConfig := GlobalConfig
+	newConfig := &Config{}
+	if err := viper.Unmarshal(newConfig); err != nil {
+		return fmt.Errorf("重新加载配置失败: %v", err)
+	}
 
-		GlobalConfig = newConfig
-		fmt.Printf("配置重新加载成功\n")
+	// 验证新配置
+	if err := validateConfig(newConfig); err != nil {
+		return fmt.Errorf("新配置验证失败: %v", err)
+	}
 
-		// 可以在这里添加配置变更的回调处理
-		handleConfigChange(oldConfig, newConfig)
-	})
+	GlobalConfig = newConfig
+	fmt.Printf("配置重新加载成功\n")
 
+	handleConfigChange(oldConfig, newConfig)
 	return nil
 }
 
@@ -181,8 +228,10 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
 	viper.SetDefault("server.idle_timeout", "60s")
+	viper.SetDefault("server.shutdown_timeout", "30s")
 
 	// 数据库默认配置
+	viper.SetDefault("database.default.driver", DriverMySQL)
 	viper.SetDefault("database.default.host", "127.0.0.1")
 	viper.SetDefault("database.default.port", 3306)
 	viper.SetDefault("database.default.maxIdle", 10)
@@ -198,6 +247,7 @@ func setDefaults() {
 	viper.SetDefault("log.max_backups", 3)
 	viper.SetDefault("log.max_age", 28)
 	viper.SetDefault("log.compress", true)
+	viper.SetDefault("log.service_dir", "logs/services")
 
 	// 监控默认配置
 	viper.SetDefault("monitor.enabled", true)
@@ -206,13 +256,27 @@ func setDefaults() {
 	viper.SetDefault("monitor.check_interval", "30s")
 	viper.SetDefault("monitor.timeout", "10s")
 	viper.SetDefault("monitor.retention_days", 7)
+	viper.SetDefault("monitor.retention_time", "03:00")
+	viper.SetDefault("monitor.retention_keep_last", 100)
+	viper.SetDefault("monitor.watchdog_interval", "5s")
+	viper.SetDefault("monitor.unhealthy_threshold", 3)
+	viper.SetDefault("monitor.alert_format", "auto")
+	viper.SetDefault("monitor.alert_retries", 3)
+	viper.SetDefault("monitor.alert_backoff", "2s")
+	viper.SetDefault("monitor.alert_silence", "5m")
 
 	// 安全默认配置
 	viper.SetDefault("security.enable_auth", false)
 	viper.SetDefault("security.token_expiry", "24h")
+	viper.SetDefault("security.refresh_expiry", "168h")
+	viper.SetDefault("security.admin_username", "admin")
 	viper.SetDefault("security.rate_limit_enabled", true)
 	viper.SetDefault("security.rate_limit_rps", 100)
+	viper.SetDefault("security.rate_limit_burst", 200)
+	viper.SetDefault("security.cmd_rate_limit_rps", 1)
+	viper.SetDefault("security.cmd_rate_limit_burst", 5)
 	viper.SetDefault("security.tls_enabled", false)
+	viper.SetDefault("security.client_auth", ClientAuthNone)
 }
 
 // validateConfig 验证配置
@@ -228,23 +292,87 @@ func validateConfig(cfg *Config) error {
 	}
 
 	for name, db := range cfg.Database {
-		if db.Host == "" || db.Name == "" || db.User == "" {
-			return fmt.Errorf("数据库 %s 配置不完整", name)
+		switch db.Driver {
+		case "", DriverMySQL:
+			if db.Host == "" || db.Name == "" || db.User == "" {
+				return fmt.Errorf("数据库 %s 配置不完整", name)
+			}
+		case DriverSQLite:
+		default:
+			return fmt.Errorf("数据库 %s 的驱动 %s 不支持，可选值: mysql, sqlite", name, db.Driver)
 		}
 	}
 
+	switch cfg.Monitor.AlertFormat {
+	case "", "auto", "json", "dingtalk", "wecom", "feishu", "slack":
+	default:
+		return fmt.Errorf("无效的 alert_format: %s", cfg.Monitor.AlertFormat)
+	}
+
+	if _, err := time.Parse("15:04", cfg.Monitor.RetentionTime); err != nil {
+		return fmt.Errorf("无效的 retention_time: %s, 格式为 HH:MM", cfg.Monitor.RetentionTime)
+	}
+
 	// 验证安全配置
 	if cfg.Security.EnableAuth && cfg.Security.JWTSecret == "" {
 		return fmt.Errorf("启用认证时必须配置JWT密钥")
 	}
 
+	if cfg.Security.RateLimitEnabled && (cfg.Security.RateLimitRPS <= 0 || cfg.Security.CmdRateLimitRPS <= 0) {
+		return fmt.Errorf("启用限流时 rate_limit_rps 和 cmd_rate_limit_rps 必须大于0")
+	}
+
+	if _, err := ParseIPPrefixes(cfg.Security.AllowedIPs); err != nil {
+		return fmt.Errorf("allowed_ips 配置错误: %v", err)
+	}
+
+	if _, err := ParseIPPrefixes(cfg.Security.TrustedProxies); err != nil {
+		return fmt.Errorf("trusted_proxies 配置错误: %v", err)
+	}
+
 	if cfg.Security.TLSEnabled && (cfg.Security.CertFile == "" || cfg.Security.KeyFile == "") {
 		return fmt.Errorf("启用TLS时必须配置证书文件")
 	}
 
+	switch cfg.Security.ClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if !cfg.Security.TLSEnabled || cfg.Security.ClientCAFile == "" {
+			return fmt.Errorf("启用客户端证书认证时必须启用TLS并配置 client_ca_file")
+		}
+	default:
+		return fmt.Errorf("无效的 client_auth: %s", cfg.Security.ClientAuth)
+	}
+
 	return nil
 }
 
+// ParseIPPrefixes 解析IP或CIDR列表，单个IP转换为只包含该IP的网段，支持IPv4和IPv6
+func ParseIPPrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("无效的CIDR: %s", entry)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("无效的IP: %s", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
 // GetConfig 获取全局配置
 func GetConfig() *Config {
 	return GlobalConfig
@@ -276,11 +404,18 @@ func GetRedisConfig(name string) (*RedisConfig, error) {
 	return nil, fmt.Errorf("redis配置 %s 不存在", name)
 }
 
-// ReloadConfig 重新加载配置
+// ReloadConfig 重新读取配置文件并触发变更回调，不会重复注册文件监听
 func ReloadConfig() error {
-	return InitConfig(configPath)
-} // c
-// reateDefaultConfig 创建默认配置文件
+	if GlobalConfig == nil {
+		return InitConfig(configPath)
+	}
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	return applyConfig()
+}
+
+// createDefaultConfig 创建默认配置文件
 func createDefaultConfig(configFile string) error {
 	defaultConfig := `# Go服务管理工具配置文件
 
@@ -296,14 +431,14 @@ server:
   read_timeout: "30s"
   write_timeout: "30s"
   idle_timeout: "60s"
+  shutdown_timeout: "30s"
 
 database:
+  # driver 为 sqlite 时使用内置的 SQLite 数据库，不需要安装 MySQL
+  # 使用 MySQL 时改为 driver: "mysql" 并配置 host、port、user、pwd、name
   default:
-    host: "127.0.0.1"
-    port: 3306
-    user: "root"
-    pwd: ""
-    name: "go_service"
+    driver: "sqlite"
+    path: "data/go_service.db"
     maxIdle: 10
     maxOpen: 100
     maxLifetime: "1h"
@@ -317,6 +452,7 @@ log:
   max_backups: 3
   max_age: 28
   compress: true
+  service_dir: "logs/services"
 
 monitor:
   enabled: true
@@ -325,18 +461,37 @@ monitor:
   check_interval: "30s"
   timeout: "10s"
   retention_days: 7
+  retention_time: "03:00"
+  retention_keep_last: 100
+  retention_archive_dir: ""
+  watchdog_interval: "5s"
+  unhealthy_threshold: 3
   alert_webhook: ""
+  alert_format: "auto"
+  alert_secret: ""
+  alert_retries: 3
+  alert_backoff: "2s"
+  alert_silence: "5m"
 
 security:
   enable_auth: false
   jwt_secret: ""
   token_expiry: "24h"
+  refresh_expiry: "168h"
+  admin_username: "admin"
+  admin_password: ""
   rate_limit_enabled: true
   rate_limit_rps: 100
+  rate_limit_burst: 200
+  cmd_rate_limit_rps: 1
+  cmd_rate_limit_burst: 5
   allowed_ips: []
+  trusted_proxies: []
   tls_enabled: false
   cert_file: ""
   key_file: ""
+  client_ca_file: ""
+  client_auth: "none"
 `
 
 	return os.WriteFile(configFile, []byte(defaultConfig), 0644)
@@ -349,6 +504,15 @@ func handleConfigChange(oldConfig, newConfig *Config) {
 		fmt.Printf("服务器端口从 %s 变更为 %s，需要重启服务器\n", oldConfig.Server.Port, newConfig.Server.Port)
 	}
 
+	if oldConfig.Server.Host != newConfig.Server.Host || oldConfig.Server.ReadTimeout != newConfig.Server.ReadTimeout ||
+		oldConfig.Server.WriteTimeout != newConfig.Server.WriteTimeout || oldConfig.Server.IdleTimeout != newConfig.Server.IdleTimeout {
+		fmt.Printf("服务器监听地址或超时配置已变更，需要重启服务器\n")
+	}
+
+	if oldConfig.Security.TLSEnabled != newConfig.Security.TLSEnabled {
+		fmt.Printf("TLS开关已变更，需要重启服务器\n")
+	}
+
 	if oldConfig.Monitor.Enabled != newConfig.Monitor.Enabled {
 		if newConfig.Monitor.Enabled {
 			fmt.Printf("监控功能已启用\n")
@@ -364,6 +528,26 @@ func handleConfigChange(oldConfig, newConfig *Config) {
 			fmt.Printf("限流功能已禁用\n")
 		}
 	}
+
+	changeHandlers.RLock()
+	handlers := append([]func(oldConfig, newConfig *Config){}, changeHandlers.handlers...)
+	changeHandlers.RUnlock()
+	for _, handler := range handlers {
+		handler(oldConfig, newConfig)
+	}
+}
+
+// changeHandlers 配置变更回调
+var changeHandlers struct {
+	sync.RWMutex
+	handlers []func(oldConfig, newConfig *Config)
+}
+
+// OnChange 注册配置变更回调，配置文件变化或 UpdateConfig 成功后调用
+func OnChange(handler func(oldConfig, newConfig *Config)) {
+	changeHandlers.Lock()
+	defer changeHandlers.Unlock()
+	changeHandlers.handlers = append(changeHandlers.handlers, handler)
 }
 
 // UpdateConfig 更新配置
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	authService *service.AuthService
+}
+
+func NewAuthController() *AuthController {
+	return &AuthController{
+		authService: service.NewAuthService(global.GetDefaultDb()),
+	}
+}
+
+// LoginPage 登录页
+func (s *AuthController) LoginPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.html", gin.H{})
+}
+
+// Login 登录
+func (s *AuthController) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := s.authService.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Refresh 使用刷新令牌换取新的令牌
+func (s *AuthController) Refresh(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := s.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Logout 退出登录，当前用户已签发的令牌全部失效
+func (s *AuthController) Logout(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		common.Error(c, "未开启认证")
+		return
+	}
+
+	if err := s.authService.Logout(c.Request.Context(), user.Id); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "已退出登录"})
+}
+
+// Me 当前登录用户
+func (s *AuthController) Me(c *gin.Context) {
+	common.Success(c, middleware.CurrentUser(c))
+}
@@ -41,7 +41,7 @@ func (b *BatchController) BatchOperation(c *gin.Context) {
 	}
 
 	// 使用服务层的批量操作方法
-	results := b.commandService.BatchOperation(c.Request.Context(), req.ServiceIds, req.Operation)
+	results, batchId := b.commandService.BatchOperation(c.Request.Context(), req.ServiceIds, req.Operation)
 
 	// 统计成功数量
 	successCount := 0
@@ -55,6 +55,7 @@ func (b *BatchController) BatchOperation(c *gin.Context) {
 		"operation":     req.Operation,
 		"total":         len(req.ServiceIds),
 		"success_count": successCount,
+		"batch_id":      batchId,
 		"results":       results,
 	})
 }
@@ -97,11 +98,11 @@ func (b *BatchController) StartAll(c *gin.Context) {
 	}
 
 	// 批量启动服务
-	results := b.commandService.BatchOperation(c.Request.Context(), serviceIds, "start")
+	results, batchId := b.commandService.BatchOperation(c.Request.Context(), serviceIds, "start")
 
 	// 为已运行的服务添加跳过记录
 	for _, service := range services {
-		if service.Status == 1 {
+		if service.Status != 0 {
 			results = append(results, map[string]interface{}{
 				"service_id":   service.Id,
 				"service_name": service.Name,
@@ -124,6 +125,7 @@ func (b *BatchController) StartAll(c *gin.Context) {
 		"operation":     "start_all",
 		"total":         len(services),
 		"success_count": successCount,
+		"batch_id":      batchId,
 		"results":       results,
 	})
 }
@@ -150,7 +152,7 @@ func (b *BatchController) StopAll(c *gin.Context) {
 	// 筛选出需要停止的服务ID
 	var serviceIds []int64
 	for _, service := range services {
-		if service.Status == 1 { // 只停止运行状态的服务
+		if service.Status != 0 { // 只停止运行状态的服务
 			serviceIds = append(serviceIds, service.Id)
 		}
 	}
@@ -166,7 +168,7 @@ func (b *BatchController) StopAll(c *gin.Context) {
 	}
 
 	// 批量停止服务
-	results := b.commandService.BatchOperation(c.Request.Context(), serviceIds, "stop")
+	results, batchId := b.commandService.BatchOperation(c.Request.Context(), serviceIds, "stop")
 
 	// 为已停止的服务添加跳过记录
 	for _, service := range services {
@@ -193,6 +195,7 @@ func (b *BatchController) StopAll(c *gin.Context) {
 		"operation":     "stop_all",
 		"total":         len(services),
 		"success_count": successCount,
+		"batch_id":      batchId,
 		"results":       results,
 	})
 }
@@ -219,7 +222,7 @@ func (b *BatchController) RestartAll(c *gin.Context) {
 	// 筛选出需要重启的服务ID（只重启运行中的服务）
 	var serviceIds []int64
 	for _, service := range services {
-		if service.Status == 1 {
+		if service.Status != 0 {
 			serviceIds = append(serviceIds, service.Id)
 		}
 	}
@@ -235,7 +238,7 @@ func (b *BatchController) RestartAll(c *gin.Context) {
 	}
 
 	// 批量重启服务
-	results := b.commandService.BatchOperation(c.Request.Context(), serviceIds, "restart")
+	results, batchId := b.commandService.BatchOperation(c.Request.Context(), serviceIds, "restart")
 
 	// 统计成功数量
 	successCount := 0
@@ -249,6 +252,7 @@ func (b *BatchController) RestartAll(c *gin.Context) {
 		"operation":     "restart_all",
 		"total":         len(serviceIds),
 		"success_count": successCount,
+		"batch_id":      batchId,
 		"results":       results,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/model"
+	"go_service/app/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService *service.HealthService
+}
+
+func NewHealthController() *HealthController {
+	return &HealthController{
+		healthService: service.NewHealthService(global.GetDefaultDb()),
+	}
+}
+
+// Live 存活检查，进程能响应即返回 200
+func (s *HealthController) Live(c *gin.Context) {
+	common.Success(c, s.healthService.Liveness())
+}
+
+// Ready 就绪检查，依赖检查全部通过时返回 200，否则返回 503
+func (s *HealthController) Ready(c *gin.Context) {
+	s.respondReadiness(c, s.healthService.Readiness(c.Request.Context(), false))
+}
+
+// Health 就绪检查并附带服务数量统计
+func (s *HealthController) Health(c *gin.Context) {
+	s.respondReadiness(c, s.healthService.Readiness(c.Request.Context(), true))
+}
+
+// respondReadiness 按就绪状态返回 200 或 503
+func (s *HealthController) respondReadiness(c *gin.Context, result *model.ReadinessModel) {
+	if result.Status == model.HealthStatusUp {
+		common.Success(c, result)
+		return
+	}
+	c.JSON(http.StatusServiceUnavailable, common.Response{
+		Code: common.ErrCodeNotReady,
+		Msg:  common.ErrNotReady.Message,
+		Data: result,
+	})
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LogController struct {
+	logService       *service.LogService
+	retentionService *service.RetentionService
+}
+
+func NewLogController() *LogController {
+	return &LogController{
+		logService:       service.NewLogService(global.GetDefaultDb()),
+		retentionService: service.NewRetentionService(global.GetDefaultDb()),
+	}
+}
+
+// List 查询操作日志，只返回有权限的服务的日志
+func (s *LogController) List(c *gin.Context) {
+	req, ok := bindLogQuery(c)
+	if !ok {
+		return
+	}
+	req.Scope = middleware.ServiceScope(c)
+
+	result, err := s.logService.QueryLogs(c.Request.Context(), req)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// ServiceLogs 查询单个服务的操作日志
+func (s *LogController) ServiceLogs(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	req, ok := bindLogQuery(c)
+	if !ok {
+		return
+	}
+	req.ServiceId = &serviceId
+
+	result, err := s.logService.QueryLogs(c.Request.Context(), req)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Export 导出匹配的全部操作日志，format 为 csv(默认)或 jsonl
+func (s *LogController) Export(c *gin.Context) {
+	req, ok := bindLogQuery(c)
+	if !ok {
+		return
+	}
+	req.Scope = middleware.ServiceScope(c)
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		common.Error(c, "format 只能为 csv 或 jsonl")
+		return
+	}
+
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+
+	// 查到第一批数据后才写响应头，之前出错时仍可返回错误信息
+	started := false
+	begin := func() {
+		if started {
+			return
+		}
+		started = true
+
+		filename := fmt.Sprintf("service_log-%s.%s", time.Now().Format("20060102-150405"), format)
+		if format == "csv" {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			c.Header("Content-Type", "application/x-ndjson")
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Status(http.StatusOK)
+
+		// 导出大量日志时不受 server.write_timeout 限制
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("取消日志导出写超时失败: %v", err)
+		}
+		if format == "csv" {
+			csvWriter.Write([]string{"id", "service_id", "operation", "status", "duration", "created_at", "actor", "client_ip", "request_id", "trigger", "batch_id", "output", "error"})
+		}
+	}
+
+	err := s.logService.ExportLogs(c.Request.Context(), req, func(batch []model.ServiceLog) error {
+		begin()
+		for i := range batch {
+			entry := &batch[i]
+			if format == "jsonl" {
+				if err := encoder.Encode(entry); err != nil {
+					return err
+				}
+				continue
+			}
+			csvWriter.Write([]string{
+				strconv.FormatInt(entry.Id, 10),
+				strconv.FormatInt(entry.ServiceId, 10),
+				entry.Operation,
+				entry.Status,
+				strconv.FormatInt(entry.Duration, 10),
+				entry.CreatedAt.Format(time.RFC3339),
+				entry.Actor,
+				entry.ClientIp,
+				entry.RequestId,
+				entry.Trigger,
+				entry.BatchId,
+				entry.Output,
+				entry.Error,
+			})
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			common.HandleBusinessError(c, err)
+			return
+		}
+		// 已开始输出文件，只能中断
+		log.Printf("导出操作日志失败: %v", err)
+		return
+	}
+
+	begin()
+	csvWriter.Flush()
+	c.Writer.Flush()
+}
+
+// Stats 操作日志统计
+func (s *LogController) Stats(c *gin.Context) {
+	stats, err := s.logService.GetLogStats(c.Request.Context())
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, stats)
+}
+
+// RetentionRuns 最近的日志清理记录，limit 默认20，最多100
+func (s *LogController) RetentionRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	runs, err := s.retentionService.ListRuns(c.Request.Context(), limit)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, runs)
+}
+
+// RunRetention 立即在后台执行一次日志清理，返回清理记录
+func (s *LogController) RunRetention(c *gin.Context) {
+	run, err := s.retentionService.Run("manual")
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, run)
+}
+
+// bindLogQuery 绑定并解析操作日志查询参数，失败时已返回错误
+func bindLogQuery(c *gin.Context) (*model.LogListRequest, bool) {
+	var req model.LogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return nil, false
+	}
+
+	var err error
+	if req.SinceTime, err = parseQueryTime(req.Since); err != nil {
+		common.Error(c, "无效的since参数")
+		return nil, false
+	}
+	if req.UntilTime, err = parseQueryTime(req.Until); err != nil {
+		common.Error(c, "无效的until参数")
+		return nil, false
+	}
+	return &req, true
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationController() *NotificationController {
+	return &NotificationController{
+		notificationService: service.NewNotificationService(global.GetDefaultDb()),
+	}
+}
+
+// Channels 通知渠道列表
+func (s *NotificationController) Channels(c *gin.Context) {
+	channels, err := s.notificationService.ListChannels(c.Request.Context())
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, channels)
+}
+
+// SaveChannel 添加或修改通知渠道，id 为空时添加
+func (s *NotificationController) SaveChannel(c *gin.Context) {
+	channel := model.NotificationChannelModel{Enabled: true}
+	if err := c.ShouldBindJSON(&channel); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := s.notificationService.SaveChannel(c.Request.Context(), &channel); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, channel)
+}
+
+// DeleteChannel 删除通知渠道
+func (s *NotificationController) DeleteChannel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	if err := s.notificationService.DeleteChannel(c.Request.Context(), id); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "删除成功"})
+}
+
+// Rules 通知规则列表
+func (s *NotificationController) Rules(c *gin.Context) {
+	rules, err := s.notificationService.ListRules(c.Request.Context())
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, rules)
+}
+
+// SaveRule 添加或修改通知规则，id 为空时添加
+func (s *NotificationController) SaveRule(c *gin.Context) {
+	rule := model.NotificationRuleModel{Enabled: true, SendResolved: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := s.notificationService.SaveRule(c.Request.Context(), &rule); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, rule)
+}
+
+// DeleteRule 删除通知规则
+func (s *NotificationController) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	if err := s.notificationService.DeleteRule(c.Request.Context(), id); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "删除成功"})
+}
+
+// Silences 静默窗口列表，all=true 时包含已结束的
+func (s *NotificationController) Silences(c *gin.Context) {
+	silences, err := s.notificationService.ListSilences(c.Request.Context(), c.Query("all") != "true")
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, silences)
+}
+
+// AddSilence 添加静默窗口，starts_at 为空时立即生效
+func (s *NotificationController) AddSilence(c *gin.Context) {
+	var silence model.NotificationSilenceModel
+	if err := c.ShouldBindJSON(&silence); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	silence.Id = 0
+	if user := middleware.CurrentUser(c); user != nil {
+		silence.CreatedBy = user.Username
+	}
+
+	if err := s.notificationService.CreateSilence(c.Request.Context(), &silence); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, silence)
+}
+
+// ExpireSilence 立即结束静默窗口
+func (s *NotificationController) ExpireSilence(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	if err := s.notificationService.ExpireSilence(c.Request.Context(), id); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "静默已结束"})
+}
+
+// Incidents 未恢复的问题
+func (s *NotificationController) Incidents(c *gin.Context) {
+	common.Success(c, service.CurrentIncidents())
+}
@@ -0,0 +1,281 @@
+package controller
+
+import (
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type OutputController struct {
+	outputLogService    *service.OutputLogService
+	outputStreamService *service.OutputStreamService
+	authService         *service.AuthService
+}
+
+func NewOutputController() *OutputController {
+	return &OutputController{
+		outputLogService:    service.NewOutputLogService(global.GetDefaultDb()),
+		outputStreamService: service.NewOutputStreamService(global.GetDefaultDb()),
+		authService:         service.NewAuthService(global.GetDefaultDb()),
+	}
+}
+
+// outputHeartbeatInterval 实时输出连接的心跳间隔，避免空闲连接被代理断开
+const outputHeartbeatInterval = 15 * time.Second
+
+var outputUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	// 与 Cors 中间件一致，允许任意来源
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Tail 读取服务最后若干行输出
+func (s *OutputController) Tail(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	var req model.OutputTailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := s.outputLogService.Tail(c.Request.Context(), serviceId, req.Lines)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Range 按字节读取服务输出
+func (s *OutputController) Range(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	var req model.OutputRangeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := s.outputLogService.ReadRange(c.Request.Context(), serviceId, req.Offset, req.Length)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Window 按时间范围读取服务输出
+func (s *OutputController) Window(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	var req model.OutputWindowRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	since, err := parseQueryTime(req.Since)
+	if err != nil {
+		common.Error(c, "无效的since参数")
+		return
+	}
+	until, err := parseQueryTime(req.Until)
+	if err != nil {
+		common.Error(c, "无效的until参数")
+		return
+	}
+
+	result, err := s.outputLogService.ReadWindow(c.Request.Context(), serviceId, since, until, req.Limit)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// StreamTicket 签发建立实时输出连接的一次性票据，票据只能用于同一服务的 logs/stream，未开启认证时返回空票据
+func (s *OutputController) StreamTicket(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		common.Success(c, model.StreamTicketResponse{})
+		return
+	}
+
+	path := strings.TrimSuffix(c.Request.URL.Path, "-ticket")
+	result, err := s.authService.IssueStreamTicket(user, path)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Stream 实时推送服务输出，WebSocket 握手请求使用 WebSocket，否则使用 Server-Sent Events
+func (s *OutputController) Stream(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	var req model.OutputStreamRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	var streams []string
+	for _, stream := range strings.Split(req.Stream, ",") {
+		if stream = strings.TrimSpace(stream); stream != "" {
+			streams = append(streams, stream)
+		}
+	}
+
+	subscription, err := s.outputStreamService.Subscribe(c.Request.Context(), serviceId, req.Backlog, req.Filter, streams)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	defer subscription.Close()
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		s.streamWebSocket(c, subscription)
+		return
+	}
+	s.streamSSE(c, subscription)
+}
+
+// streamSSE 以 Server-Sent Events 推送，事件类型 line 为一行输出，dropped 为丢弃的行数，ping 为心跳
+func (s *OutputController) streamSSE(c *gin.Context, subscription *service.OutputSubscription) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// 长连接不受 server.write_timeout 限制
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("取消输出流写超时失败: %v", err)
+	}
+
+	for _, line := range subscription.Backlog {
+		c.SSEvent("line", line)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(outputHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case line, ok := <-subscription.Lines():
+			if !ok {
+				return
+			}
+			if dropped := subscription.TakeDropped(); dropped > 0 {
+				c.SSEvent("dropped", gin.H{"count": dropped})
+			}
+			c.SSEvent("line", line)
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+		}
+		c.Writer.Flush()
+	}
+}
+
+// outputMessage WebSocket 推送的消息
+type outputMessage struct {
+	Type  string                 `json:"type"` // line, dropped
+	Line  *model.OutputLineModel `json:"line,omitempty"`
+	Count int                    `json:"count,omitempty"`
+}
+
+// streamWebSocket 以 WebSocket 推送，每条消息为一个 JSON 对象，心跳使用 ping 控制帧
+func (s *OutputController) streamWebSocket(c *gin.Context, subscription *service.OutputSubscription) {
+	conn, err := outputUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已向客户端返回错误
+		return
+	}
+	defer conn.Close()
+
+	// 读取客户端消息以处理关闭和 pong，连接断开时结束推送
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := range subscription.Backlog {
+		if err := conn.WriteJSON(outputMessage{Type: "line", Line: &subscription.Backlog[i]}); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(outputHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case line, ok := <-subscription.Lines():
+			if !ok {
+				return
+			}
+			if dropped := subscription.TakeDropped(); dropped > 0 {
+				if err := conn.WriteJSON(outputMessage{Type: "dropped", Count: dropped}); err != nil {
+					return
+				}
+			}
+			if err := conn.WriteJSON(outputMessage{Type: "line", Line: &line}); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseQueryTime 解析查询参数中的时间，支持 RFC3339 和本地时间 2006-01-02 15:04:05，为空时返回零值
+func parseQueryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+}
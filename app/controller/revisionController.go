@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionController struct {
+	revisionService   *service.RevisionService
+	serviceService    *service.ServiceService
+	permissionService *service.PermissionService
+}
+
+func NewRevisionController() *RevisionController {
+	db := global.GetDefaultDb()
+	return &RevisionController{
+		revisionService:   service.NewRevisionService(db),
+		serviceService:    service.NewServiceService(db),
+		permissionService: service.NewPermissionService(db),
+	}
+}
+
+// List 服务的所有配置版本，按版本号倒序
+func (s *RevisionController) List(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	revisions, err := s.revisionService.ListRevisions(c.Request.Context(), serviceId)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, revisions)
+}
+
+// Get 服务的一个配置版本
+func (s *RevisionController) Get(c *gin.Context) {
+	serviceId, revision, ok := revisionParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := s.revisionService.GetRevision(c.Request.Context(), serviceId, revision)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// Diff 比较两个版本，to 为空时与最新版本比较
+func (s *RevisionController) Diff(c *gin.Context) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		common.Error(c, "无效的from参数")
+		return
+	}
+	to := 0
+	if value := c.Query("to"); value != "" {
+		if to, err = strconv.Atoi(value); err != nil || to <= 0 {
+			common.Error(c, "无效的to参数")
+			return
+		}
+	}
+
+	diff, err := s.revisionService.DiffRevisions(c.Request.Context(), serviceId, from, to)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, diff)
+}
+
+// Restore 恢复到指定版本，服务已删除时重新添加，恢复后分组变化时还要求在该分组上拥有 admin
+func (s *RevisionController) Restore(c *gin.Context) {
+	serviceId, revision, ok := revisionParams(c)
+	if !ok {
+		return
+	}
+
+	if user := middleware.CurrentUser(c); user != nil {
+		target, err := s.revisionService.GetRevision(c.Request.Context(), serviceId, revision)
+		if err != nil {
+			common.HandleBusinessError(c, err)
+			return
+		}
+		current, err := s.serviceService.GetServiceById(c.Request.Context(), serviceId)
+		if err != nil && err != common.ErrServiceNotFound {
+			common.HandleBusinessError(c, err)
+			return
+		}
+		if target.Snapshot != nil && (current == nil || current.Group != target.Snapshot.Group) {
+			if err := s.permissionService.CheckGroup(c.Request.Context(), user, model.RoleAdmin, target.Snapshot.Group); err != nil {
+				common.HandleBusinessError(c, err)
+				return
+			}
+		}
+	}
+
+	restored, err := s.revisionService.Restore(c.Request.Context(), serviceId, revision)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, restored)
+}
+
+// Deleted 已删除的服务及删除时的版本，可通过恢复接口重新添加
+func (s *RevisionController) Deleted(c *gin.Context) {
+	revisions, err := s.revisionService.ListDeleted(c.Request.Context())
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, revisions)
+}
+
+// revisionParams 解析路径中的服务ID和版本号，失败时已返回错误
+func revisionParams(c *gin.Context) (int64, int, bool) {
+	serviceId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return 0, 0, false
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision <= 0 {
+		common.Error(c, "无效的版本号")
+		return 0, 0, false
+	}
+	return serviceId, revision, true
+}
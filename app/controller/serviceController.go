@@ -3,6 +3,7 @@ package controller
 import (
 	"go_service/app/common"
 	"go_service/app/global"
+	"go_service/app/middleware"
 	"go_service/app/model"
 	"go_service/app/service"
 	"strconv"
@@ -47,7 +48,7 @@ func (s *ServiceController) FindById(c *gin.Context) {
 		return
 	}
 	
-	serviceModel, err := s.serviceService.GetServiceById(c.Request.Context(), serviceId)
+	serviceModel, err := s.serviceService.GetServiceStatusById(c.Request.Context(), serviceId)
 	if err != nil {
 		common.HandleBusinessError(c, err)
 		return
@@ -80,9 +81,12 @@ func (s *ServiceController) FindAll(c *gin.Context) {
 		req = model.ServiceListRequest{Page: 1, PageSize: 100}
 	}
 
+	// 只返回当前用户有权限的服务
+	req.Scope = middleware.ServiceScope(c)
+
 	// 如果没有分页参数，获取所有服务
 	if req.Page == 0 && req.PageSize == 0 {
-		services, err := s.serviceService.GetAllServicesWithStatus(c.Request.Context())
+		services, err := s.serviceService.FindServicesWithStatus(c.Request.Context(), &req)
 		if err != nil {
 			common.HandleBusinessError(c, err)
 			return
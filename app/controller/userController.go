@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserController struct {
+	userService       *service.UserService
+	permissionService *service.PermissionService
+}
+
+func NewUserController() *UserController {
+	db := global.GetDefaultDb()
+	return &UserController{
+		userService:       service.NewUserService(db),
+		permissionService: service.NewPermissionService(db),
+	}
+}
+
+// List 用户列表
+func (s *UserController) List(c *gin.Context) {
+	users, err := s.userService.ListUsers(c.Request.Context())
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, users)
+}
+
+// Add 添加用户
+func (s *UserController) Add(c *gin.Context) {
+	var req model.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := s.userService.CreateUser(c.Request.Context(), &req)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, user)
+}
+
+// Update 修改用户角色、启用状态或密码
+func (s *UserController) Update(c *gin.Context) {
+	var req model.UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := s.userService.UpdateUser(c.Request.Context(), &req, currentUserId(c))
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, user)
+}
+
+// Delete 删除用户
+func (s *UserController) Delete(c *gin.Context) {
+	userId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	if err := s.userService.DeleteUser(c.Request.Context(), userId, currentUserId(c)); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "删除成功"})
+}
+
+// Permissions 授权列表，可按 user_id 过滤
+func (s *UserController) Permissions(c *gin.Context) {
+	userId, _ := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	grants, err := s.permissionService.ListGrants(c.Request.Context(), userId)
+	if err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, grants)
+}
+
+// Grant 授予用户在服务或分组上的角色
+func (s *UserController) Grant(c *gin.Context) {
+	var grant model.UserPermissionModel
+	if err := c.ShouldBindJSON(&grant); err != nil {
+		common.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	grant.Id = 0
+
+	if err := s.permissionService.Grant(c.Request.Context(), &grant); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, grant)
+}
+
+// Revoke 删除授权
+func (s *UserController) Revoke(c *gin.Context) {
+	grantId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, "无效的ID参数")
+		return
+	}
+
+	if err := s.permissionService.Revoke(c.Request.Context(), grantId); err != nil {
+		common.HandleBusinessError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "删除成功"})
+}
+
+// currentUserId 当前登录用户ID，未开启认证时为0
+func currentUserId(c *gin.Context) int64 {
+	if user := middleware.CurrentUser(c); user != nil {
+		return user.Id
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"go_service/app/config"
+	"go_service/app/global"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// RunCopyDb 将一个数据库的数据复制到另一个数据库，用于在 MySQL 和 SQLite 之间迁移
+// 两个数据库都在配置文件的 database 中配置，复制前对目标数据库执行迁移，目标数据库的表必须为空
+//
+//	go_service copydb -c config.yml -from default -to sqlite
+func RunCopyDb(args []string) {
+	flags := flag.NewFlagSet("copydb", flag.ExitOnError)
+	configFile := flags.String("c", "config.yml", "specify config file")
+	from := flags.String("from", "", "源数据库在 database 中的名称")
+	to := flags.String("to", "", "目标数据库在 database 中的名称")
+	flags.Parse(args)
+
+	if *from == "" || *to == "" || *from == *to {
+		fmt.Fprintln(os.Stderr, "用法: go_service copydb [-c config.yml] -from <源数据库> -to <目标数据库>")
+		os.Exit(2)
+	}
+
+	if err := config.InitConfig(*configFile); err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	src, _, err := openCopyDatabase(*from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开源数据库 %s 失败: %v\n", *from, err)
+		os.Exit(1)
+	}
+	dst, dstConfig, err := openCopyDatabase(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开目标数据库 %s 失败: %v\n", *to, err)
+		os.Exit(1)
+	}
+
+	if _, err := global.MigrateUp(dst, dstConfig.Driver); err != nil {
+		fmt.Fprintf(os.Stderr, "迁移目标数据库 %s 失败: %v\n", *to, err)
+		os.Exit(1)
+	}
+
+	err = global.CopyDatabase(src, dst, func(table string, rows int64) {
+		fmt.Printf("%-24s %d 条\n", table, rows)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "复制数据失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("已将数据库 %s 的数据复制到 %s\n", *from, *to)
+}
+
+// openCopyDatabase 按名称打开配置中的数据库
+func openCopyDatabase(name string) (*gorm.DB, *config.DatabaseConfig, error) {
+	cfg, err := config.GetDatabaseConfig(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := global.OpenDatabase(*cfg)
+	return db, cfg, err
+}
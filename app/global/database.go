@@ -20,14 +20,14 @@ func InitDatabase() error {
 	DatabaseClient = make(map[string]*gorm.DB, len(databaseConfig))
 	for k, v := range databaseConfig {
 		// 初始化数据库客户端
-		db, err := InitMysqlClient(v.Host, v.Port, v.User, v.Password, v.Name)
+		db, err := OpenDatabase(v)
 		if err != nil {
-			panic(fmt.Errorf("init mysql" + k + " client failed:" + err.Error()))
+			panic(fmt.Errorf("init database " + k + " client failed:" + err.Error()))
 		}
 		DatabaseClient[k] = db
 		sqlDB, err := db.DB()
 		if err != nil {
-			panic("get mysql" + k + " sqlDB failed:" + err.Error())
+			panic("get database " + k + " sqlDB failed:" + err.Error())
 		}
 
 		// 优化连接池配置
@@ -53,6 +53,31 @@ func InitDatabase() error {
 		}
 
 		fmt.Printf("数据库 %s 连接成功\n", k)
+
+		// 执行未执行的迁移
+		migrations, err := MigrateUp(db, v.Driver)
+		for _, migration := range migrations {
+			fmt.Printf("数据库 %s 执行迁移 %04d_%s\n", k, migration.Version, migration.Name)
+		}
+		if err != nil {
+			panic(fmt.Errorf("database %s migrate failed: %v", k, err))
+		}
 	}
 	return nil
 }
+
+// OpenDatabase 按配置的驱动打开数据库，driver 为空时使用 MySQL
+func OpenDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case "", config.DriverMySQL:
+		return InitMysqlClient(cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
+	case config.DriverSQLite:
+		path := cfg.Path
+		if path == "" {
+			path = config.DefaultSQLitePath
+		}
+		return InitSqliteClient(path)
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,110 @@
+package global
+
+import (
+	"fmt"
+	"go_service/app/model"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// copyBatchSize 复制数据时每批读取和写入的条数
+const copyBatchSize = 500
+
+// copyTables 复制数据的表，按模型定义
+var copyTables = []interface{}{
+	&model.ServiceModel{},
+	&model.ServiceLog{},
+	&model.ServiceRevisionModel{},
+	&model.LogRetentionRunModel{},
+	&model.UserModel{},
+	&model.UserPermissionModel{},
+	&model.NotificationChannelModel{},
+	&model.NotificationRuleModel{},
+	&model.NotificationSilenceModel{},
+}
+
+// CopyDatabase 将 src 中所有表的数据复制到 dst，保留原ID，目标表必须为空
+// 每复制完一张表调用一次 progress
+func CopyDatabase(src, dst *gorm.DB, progress func(table string, rows int64)) error {
+	for _, table := range copyTables {
+		name := tableName(dst, table)
+		var count int64
+		if err := dst.Model(table).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询目标表 %s 失败: %v", name, err)
+		}
+		if count > 0 {
+			return fmt.Errorf("目标表 %s 已有 %d 条数据，只能复制到空数据库", name, count)
+		}
+	}
+
+	for _, table := range copyTables {
+		name := tableName(dst, table)
+		rows, err := copyTable(src, dst, name)
+		if err != nil {
+			return fmt.Errorf("复制表 %s 失败(已复制 %d 条): %v", name, rows, err)
+		}
+		if progress != nil {
+			progress(name, rows)
+		}
+	}
+	return nil
+}
+
+// copyTable 按 id 分批复制一张表，返回复制的条数
+// 按列读写而不使用模型，避免零值被模型的默认值替换
+func copyTable(src, dst *gorm.DB, name string) (int64, error) {
+	var rows int64
+	var lastId int64
+	for {
+		batch := []map[string]interface{}{}
+		err := src.Table(name).Where("id > ?", lastId).Order("id").Limit(copyBatchSize).Find(&batch).Error
+		if err != nil {
+			return rows, err
+		}
+		if len(batch) == 0 {
+			return rows, nil
+		}
+		if err := dst.Table(name).Create(&batch).Error; err != nil {
+			return rows, err
+		}
+		rows += int64(len(batch))
+		lastId, err = toInt64(batch[len(batch)-1]["id"])
+		if err != nil {
+			return rows, err
+		}
+		if len(batch) < copyBatchSize {
+			return rows, nil
+		}
+	}
+}
+
+// toInt64 读取到的 id 转为 int64，不同驱动返回的类型不同
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("无法识别的 id: %v", value)
+}
+
+// tableName 模型对应的表名
+func tableName(db *gorm.DB, table interface{}) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(table); err != nil {
+		return fmt.Sprintf("%T", table)
+	}
+	return stmt.Schema.Table
+}
@@ -0,0 +1,237 @@
+package global
+
+import (
+	"embed"
+	"fmt"
+	"go_service/app/config"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles 按驱动分目录的迁移脚本，文件名为 <版本>_<名称>.up.sql 和 <版本>_<名称>.down.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// createMigrationsTable 记录已执行迁移的表，MySQL 和 SQLite 通用
+const createMigrationsTable = "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+	"`version` bigint NOT NULL PRIMARY KEY, " +
+	"`name` varchar(255) NOT NULL DEFAULT '', " +
+	"`applied_at` datetime NOT NULL)"
+
+// Migration 一个版本的迁移脚本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移的执行状态，AppliedAt 为空表示未执行
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// schemaMigration schema_migrations 中的一条记录
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255)"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations 读取驱动对应的迁移脚本，按版本升序
+func LoadMigrations(driver string) ([]Migration, error) {
+	if driver == "" {
+		driver = config.DriverMySQL
+	}
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("驱动 %s 没有迁移脚本", driver)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移脚本文件名不正确: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本 %d 的脚本名称不一致: %s, %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少 up 或 down 脚本", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrationStatuses 所有迁移的执行状态，按版本升序
+func MigrationStatuses(db *gorm.DB, driver string) ([]MigrationStatus, error) {
+	migrations, applied, err := loadMigrationState(db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// MigrateUp 按版本顺序执行所有未执行的迁移，返回本次执行的迁移
+// 数据库已执行了程序中不存在的版本时返回错误，避免旧版本程序使用新的表结构
+func MigrateUp(db *gorm.DB, driver string) ([]Migration, error) {
+	migrations, applied, err := loadMigrationState(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) > 0 {
+		latest := migrations[len(migrations)-1].Version
+		for version := range applied {
+			if version > latest {
+				return nil, fmt.Errorf("数据库已迁移到版本 %d，高于程序支持的版本 %d，请升级程序", version, latest)
+			}
+		}
+	}
+
+	done := []Migration{}
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := runMigration(db, migration, migration.Up, func(tx *gorm.DB) error {
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// MigrateDown 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func MigrateDown(db *gorm.DB, driver string, steps int) ([]Migration, error) {
+	migrations, applied, err := loadMigrationState(db, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := runMigration(db, migration, migration.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// loadMigrationState 读取迁移脚本和已执行的迁移，schema_migrations 不存在时创建
+func loadMigrationState(db *gorm.DB, driver string) ([]Migration, map[int64]schemaMigration, error) {
+	migrations, err := LoadMigrations(driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Exec(createMigrationsTable).Error; err != nil {
+		return nil, nil, fmt.Errorf("创建 schema_migrations 失败: %v", err)
+	}
+
+	records := []schemaMigration{}
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("查询已执行的迁移失败: %v", err)
+	}
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return migrations, applied, nil
+}
+
+// runMigration 在事务中逐条执行脚本并更新 schema_migrations
+// MySQL 的 DDL 会隐式提交，执行失败时已执行的语句不会回滚，需要手动处理后再重新执行
+func runMigration(db *gorm.DB, migration Migration, script string, record func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("执行迁移 %04d_%s 的第 %d 条语句失败: %v", migration.Version, migration.Name, i+1, err)
+			}
+		}
+		if err := record(tx); err != nil {
+			return fmt.Errorf("记录迁移 %04d_%s 失败: %v", migration.Version, migration.Name, err)
+		}
+		return nil
+	})
+}
+
+// splitStatements 按行尾的分号拆分脚本中的语句并去掉分号，忽略 -- 开头的注释行
+func splitStatements(script string) []string {
+	statements := []string{}
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,177 @@
+package global_test
+
+import (
+	"go_service/app/global"
+	"go_service/app/model"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// baselineSchema 原 db.sql 的表结构在 SQLite 中的写法，模拟导入 db.sql 后升级的数据库
+const baselineSchema = "CREATE TABLE `service` (" +
+	"`id` integer PRIMARY KEY AUTOINCREMENT, " +
+	"`name` varchar(255) NOT NULL DEFAULT '', " +
+	"`title` varchar(255) NOT NULL DEFAULT '', " +
+	"`dir` varchar(255) NOT NULL DEFAULT '', " +
+	"`cmd_start` varchar(500) NOT NULL DEFAULT '', " +
+	"`cmd_stop` varchar(500) NOT NULL DEFAULT '', " +
+	"`cmd_restart` varchar(500) NOT NULL DEFAULT '', " +
+	"`port` integer NOT NULL DEFAULT 0, " +
+	"`health_check_url` varchar(500) DEFAULT '', " +
+	"`auto_restart` numeric DEFAULT 0, " +
+	"`max_restart_count` integer DEFAULT 3, " +
+	"`restart_interval` integer DEFAULT 30, " +
+	"`remark` varchar(500) NOT NULL DEFAULT '', " +
+	"`created_at` datetime NOT NULL DEFAULT current_timestamp, " +
+	"`updated_at` datetime NOT NULL DEFAULT current_timestamp);" +
+	"CREATE TABLE `service_log` (" +
+	"`id` integer PRIMARY KEY AUTOINCREMENT, " +
+	"`service_id` integer NOT NULL, " +
+	"`operation` varchar(50) NOT NULL, " +
+	"`status` varchar(20) NOT NULL, " +
+	"`output` text, " +
+	"`error` text, " +
+	"`duration` integer DEFAULT 0, " +
+	"`created_at` datetime NOT NULL DEFAULT current_timestamp);" +
+	"INSERT INTO `service` (`name`, `title`, `dir`, `cmd_start`, `port`) VALUES ('api', '接口', '/tmp', './api', 18080);" +
+	"INSERT INTO `service_log` (`service_id`, `operation`, `status`) VALUES (1, 'start', 'success')"
+
+// tableModels 迁移后每个模型的字段都应有对应的列
+var tableModels = []interface{}{
+	&model.ServiceModel{},
+	&model.ServiceLog{},
+	&model.ServiceRevisionModel{},
+	&model.LogRetentionRunModel{},
+	&model.UserModel{},
+	&model.UserPermissionModel{},
+	&model.NotificationChannelModel{},
+	&model.NotificationRuleModel{},
+	&model.NotificationSilenceModel{},
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := global.InitSqliteClient(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	return db
+}
+
+// assertModelColumns 检查所有模型字段在数据库中都有对应的列
+func assertModelColumns(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, value := range tableModels {
+		parsed, err := schema.Parse(value, &sync.Map{}, db.NamingStrategy)
+		if err != nil {
+			t.Fatalf("解析模型失败: %v", err)
+		}
+		if !db.Migrator().HasTable(parsed.Table) {
+			t.Fatalf("迁移后缺少表 %s", parsed.Table)
+		}
+		for _, field := range parsed.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(parsed.Table, field.DBName) {
+				t.Errorf("迁移后表 %s 缺少列 %s", parsed.Table, field.DBName)
+			}
+		}
+	}
+}
+
+func TestMigrateUpFromBaseline(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline bool
+	}{
+		{"新数据库", false},
+		{"已导入原 db.sql", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			if tt.baseline {
+				if err := db.Exec(baselineSchema).Error; err != nil {
+					t.Fatalf("导入原表结构失败: %v", err)
+				}
+			}
+
+			migrations, err := global.LoadMigrations("sqlite")
+			if err != nil {
+				t.Fatalf("读取迁移脚本失败: %v", err)
+			}
+			done, err := global.MigrateUp(db, "sqlite")
+			if err != nil {
+				t.Fatalf("执行迁移失败: %v", err)
+			}
+			if len(done) != len(migrations) {
+				t.Fatalf("执行了 %d 个迁移，期望 %d", len(done), len(migrations))
+			}
+			assertModelColumns(t, db)
+
+			if !tt.baseline {
+				return
+			}
+			// 原有数据保留，新增的列使用默认值
+			var service model.ServiceModel
+			if err := db.First(&service, 1).Error; err != nil {
+				t.Fatalf("查询原有服务失败: %v", err)
+			}
+			if service.Name != "api" || service.RunMode != model.RunModeCommand {
+				t.Fatalf("原有服务为 %+v，期望保留数据并使用 command 运行模式", service)
+			}
+			var entry model.ServiceLog
+			if err := db.First(&entry, 1).Error; err != nil {
+				t.Fatalf("查询原有日志失败: %v", err)
+			}
+			if entry.Operation != "start" || entry.Trigger != model.TriggerSystem {
+				t.Fatalf("原有日志为 %+v，期望触发方式为 system", entry)
+			}
+		})
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	db := openTestDB(t)
+	migrations, err := global.MigrateUp(db, "sqlite")
+	if err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	// 回滚到初始版本，再重新执行，每个 down 脚本都能撤销对应的 up 脚本
+	done, err := global.MigrateDown(db, "sqlite", len(migrations)-1)
+	if err != nil {
+		t.Fatalf("回滚迁移失败: %v", err)
+	}
+	if len(done) != len(migrations)-1 {
+		t.Fatalf("回滚了 %d 个迁移，期望 %d", len(done), len(migrations)-1)
+	}
+	if db.Migrator().HasTable("service_revision") || db.Migrator().HasColumn("service", "run_mode") || db.Migrator().HasColumn("service_log", "actor") {
+		t.Fatalf("回滚后仍有后续版本添加的表或列")
+	}
+	if _, err := global.MigrateUp(db, "sqlite"); err != nil {
+		t.Fatalf("回滚后重新执行迁移失败: %v", err)
+	}
+	assertModelColumns(t, db)
+}
+
+func TestMigrationVersionsMatch(t *testing.T) {
+	mysql, err := global.LoadMigrations("mysql")
+	if err != nil {
+		t.Fatalf("读取 MySQL 迁移脚本失败: %v", err)
+	}
+	sqlite, err := global.LoadMigrations("sqlite")
+	if err != nil {
+		t.Fatalf("读取 SQLite 迁移脚本失败: %v", err)
+	}
+	if len(mysql) != len(sqlite) {
+		t.Fatalf("MySQL 有 %d 个迁移，SQLite 有 %d 个", len(mysql), len(sqlite))
+	}
+	for i := range mysql {
+		if mysql[i].Version != sqlite[i].Version || mysql[i].Name != sqlite[i].Name {
+			t.Fatalf("迁移不一致: MySQL %04d_%s, SQLite %04d_%s", mysql[i].Version, mysql[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+}
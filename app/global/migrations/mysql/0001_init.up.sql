@@ -1,5 +1,7 @@
--- 服务管理工具数据库脚本
-CREATE TABLE `service` (
+-- 服务管理工具初始表结构，与原 db.sql 相同，之后的表结构变化在后续版本中添加
+-- 使用 IF NOT EXISTS，已导入原 db.sql 的数据库执行时跳过已存在的表，再由后续版本升级
+-- 创建服务表
+CREATE TABLE IF NOT EXISTS `service` (
   `id` int(11) NOT NULL AUTO_INCREMENT,
   `name` varchar(255) NOT NULL DEFAULT '' COMMENT '英文标识名称',
   `title` varchar(255) NOT NULL DEFAULT '' COMMENT '名称',
@@ -0,0 +1,14 @@
+UPDATE `service` SET `cmd_stop` = COALESCE(`cmd_stop`, ''), `cmd_restart` = COALESCE(`cmd_restart`, ''), `remark` = COALESCE(`remark`, '');
+
+ALTER TABLE `service`
+  DROP INDEX `uk_name`,
+  DROP INDEX `uk_port`,
+  MODIFY `name` varchar(255) NOT NULL DEFAULT '' COMMENT '英文标识名称',
+  MODIFY `title` varchar(255) NOT NULL DEFAULT '' COMMENT '名称',
+  MODIFY `dir` varchar(255) NOT NULL DEFAULT '' COMMENT '目录',
+  MODIFY `cmd_start` varchar(500) NOT NULL DEFAULT '' COMMENT '启动脚本',
+  MODIFY `cmd_stop` varchar(500) NOT NULL DEFAULT '' COMMENT '关闭脚本',
+  MODIFY `cmd_restart` varchar(500) NOT NULL DEFAULT '' COMMENT '重启脚本',
+  MODIFY `remark` varchar(500) NOT NULL DEFAULT '' COMMENT '备注';
+
+ALTER TABLE `service` CONVERT TO CHARACTER SET utf8mb3;
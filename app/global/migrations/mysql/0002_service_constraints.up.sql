@@ -0,0 +1,14 @@
+-- 服务表与 ServiceModel 保持一致: 名称和端口唯一，命令使用 text，字符集改为 utf8mb4
+-- 已有重复的名称或端口、名称超过100个字符时迁移失败，需要先修改这些服务
+ALTER TABLE `service` CONVERT TO CHARACTER SET utf8mb4;
+
+ALTER TABLE `service`
+  MODIFY `name` varchar(100) NOT NULL DEFAULT '' COMMENT '英文标识名称',
+  MODIFY `title` varchar(200) NOT NULL DEFAULT '' COMMENT '名称',
+  MODIFY `dir` varchar(500) NOT NULL DEFAULT '' COMMENT '目录',
+  MODIFY `cmd_start` text NOT NULL COMMENT '启动脚本',
+  MODIFY `cmd_stop` text COMMENT '关闭脚本',
+  MODIFY `cmd_restart` text COMMENT '重启脚本',
+  MODIFY `remark` text COMMENT '备注',
+  ADD UNIQUE KEY `uk_name` (`name`),
+  ADD UNIQUE KEY `uk_port` (`port`);
@@ -0,0 +1,8 @@
+ALTER TABLE `service`
+  DROP COLUMN `liveness_probe`,
+  DROP COLUMN `readiness_probe`,
+  DROP COLUMN `startup_probe`,
+  DROP COLUMN `tags`,
+  DROP COLUMN `group_name`,
+  DROP COLUMN `run_mode`,
+  DROP COLUMN `retention_days`;
@@ -0,0 +1,9 @@
+-- 服务的日志保留天数、运行模式、分组、标签和探针
+ALTER TABLE `service`
+  ADD COLUMN `retention_days` int(11) NOT NULL DEFAULT 0 COMMENT '操作日志保留天数，0使用全局配置，-1表示不清理' AFTER `restart_interval`,
+  ADD COLUMN `run_mode` varchar(20) NOT NULL DEFAULT 'command' COMMENT '运行模式: command, managed' AFTER `retention_days`,
+  ADD COLUMN `group_name` varchar(100) NOT NULL DEFAULT '' COMMENT '分组' AFTER `run_mode`,
+  ADD COLUMN `tags` varchar(500) NOT NULL DEFAULT '' COMMENT '标签，多个用逗号分隔' AFTER `group_name`,
+  ADD COLUMN `startup_probe` text COMMENT '启动探针(JSON)' AFTER `tags`,
+  ADD COLUMN `readiness_probe` text COMMENT '就绪探针(JSON)' AFTER `startup_probe`,
+  ADD COLUMN `liveness_probe` text COMMENT '存活探针(JSON)' AFTER `readiness_probe`;
@@ -0,0 +1,9 @@
+ALTER TABLE `service_log`
+  DROP KEY `idx_batch_id`,
+  DROP KEY `idx_request_id`,
+  DROP KEY `idx_actor`,
+  DROP COLUMN `batch_id`,
+  DROP COLUMN `trigger_type`,
+  DROP COLUMN `request_id`,
+  DROP COLUMN `client_ip`,
+  DROP COLUMN `actor`;
@@ -0,0 +1,10 @@
+-- 操作日志记录操作人、来源IP、请求ID、触发方式和批次
+ALTER TABLE `service_log`
+  ADD COLUMN `actor` varchar(64) NOT NULL DEFAULT '' COMMENT '操作人' AFTER `duration`,
+  ADD COLUMN `client_ip` varchar(64) NOT NULL DEFAULT '' COMMENT '请求来源IP' AFTER `actor`,
+  ADD COLUMN `request_id` varchar(64) NOT NULL DEFAULT '' COMMENT '请求ID' AFTER `client_ip`,
+  ADD COLUMN `trigger_type` varchar(20) NOT NULL DEFAULT 'system' COMMENT '触发方式: api, batch, scheduler, watchdog, system' AFTER `request_id`,
+  ADD COLUMN `batch_id` varchar(64) NOT NULL DEFAULT '' COMMENT '批量操作批次ID' AFTER `trigger_type`,
+  ADD KEY `idx_actor` (`actor`),
+  ADD KEY `idx_request_id` (`request_id`),
+  ADD KEY `idx_batch_id` (`batch_id`);
@@ -0,0 +1,16 @@
+-- 服务配置的历史版本
+-- 创建服务配置版本表
+CREATE TABLE `service_revision` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `service_id` bigint(20) NOT NULL COMMENT '服务ID',
+  `revision` int(11) NOT NULL COMMENT '服务内递增的版本号',
+  `action` varchar(20) NOT NULL COMMENT '变更类型: create, update, delete, restore, baseline',
+  `snapshot` text COMMENT '服务配置快照(JSON)',
+  `changed_fields` text COMMENT '变化的字段(JSON)',
+  `author` varchar(64) NOT NULL DEFAULT '' COMMENT '操作人',
+  `request_id` varchar(64) NOT NULL DEFAULT '' COMMENT '请求ID',
+  `comment` varchar(500) NOT NULL DEFAULT '' COMMENT '说明',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp() COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_service_revision` (`service_id`, `revision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='服务配置版本表';
@@ -0,0 +1,17 @@
+-- 操作日志清理记录
+-- 创建操作日志清理记录表
+CREATE TABLE `log_retention_run` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `trigger_type` varchar(20) NOT NULL COMMENT '触发方式: schedule, manual',
+  `status` varchar(20) NOT NULL COMMENT '状态: running, success, failed',
+  `retention_days` int(11) NOT NULL DEFAULT 0 COMMENT '全局保留天数',
+  `keep_last` int(11) NOT NULL DEFAULT 0 COMMENT '每个服务至少保留的条数',
+  `deleted_count` bigint(20) NOT NULL DEFAULT 0 COMMENT '删除条数',
+  `archived_count` bigint(20) NOT NULL DEFAULT 0 COMMENT '归档条数',
+  `archive_file` varchar(500) NOT NULL DEFAULT '' COMMENT '归档文件',
+  `detail` text COMMENT '按服务统计的删除条数(JSON)',
+  `error` text COMMENT '错误信息',
+  `started_at` datetime NOT NULL COMMENT '开始时间',
+  `finished_at` datetime DEFAULT NULL COMMENT '结束时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='操作日志清理记录表';
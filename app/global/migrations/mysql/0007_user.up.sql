@@ -0,0 +1,28 @@
+-- 用户和按服务、分组的授权
+-- 创建用户表
+CREATE TABLE `user` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `username` varchar(64) NOT NULL COMMENT '用户名',
+  `password` varchar(255) NOT NULL COMMENT '密码(bcrypt)',
+  `role` varchar(20) NOT NULL DEFAULT '' COMMENT '全局角色: viewer, operator, admin，空表示只拥有按服务或分组授予的权限',
+  `token_version` int(11) NOT NULL DEFAULT 0 COMMENT '令牌版本，退出登录时递增',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `last_login_at` datetime DEFAULT NULL COMMENT '最后登录时间',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp() COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp() COMMENT '修改时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户表';
+
+-- 创建用户授权表
+CREATE TABLE `user_permission` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `user_id` bigint(20) NOT NULL COMMENT '用户ID',
+  `role` varchar(20) NOT NULL COMMENT '角色: viewer, operator, admin',
+  `service_id` bigint(20) NOT NULL DEFAULT 0 COMMENT '授权的服务ID，0表示按分组授权',
+  `group_name` varchar(100) NOT NULL DEFAULT '' COMMENT '授权的分组，空表示按服务授权',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp() COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_service_id` (`service_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户授权表';
@@ -0,0 +1,52 @@
+-- 告警通知的渠道、规则和静默窗口
+-- 创建通知渠道表
+CREATE TABLE `notification_channel` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL COMMENT '渠道名称',
+  `type` varchar(20) NOT NULL DEFAULT 'auto' COMMENT '消息格式: auto, json, dingtalk, wecom, feishu, slack',
+  `url` varchar(500) NOT NULL COMMENT 'webhook地址',
+  `secret` varchar(255) NOT NULL DEFAULT '' COMMENT '加签密钥',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `remark` varchar(500) NOT NULL DEFAULT '' COMMENT '备注',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp() COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp() COMMENT '修改时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='通知渠道表';
+
+-- 创建通知规则表
+CREATE TABLE `notification_rule` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL COMMENT '规则名称',
+  `enabled` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `service_ids` text COMMENT '匹配的服务ID(JSON)',
+  `group_names` text COMMENT '匹配的分组(JSON)',
+  `tags` text COMMENT '匹配的标签(JSON)',
+  `event_types` text COMMENT '匹配的告警类型(JSON)，为空表示所有类型',
+  `min_level` varchar(20) NOT NULL DEFAULT '' COMMENT '最低告警级别: info, warning, critical',
+  `channel_ids` text COMMENT '通知渠道ID(JSON)',
+  `repeat_interval` int(11) NOT NULL DEFAULT 0 COMMENT '重复告警的最小通知间隔(秒)，0使用全局配置',
+  `send_resolved` tinyint(1) NOT NULL DEFAULT 1 COMMENT '恢复时是否通知',
+  `escalate_after` int(11) NOT NULL DEFAULT 0 COMMENT '问题持续多少秒未恢复时升级通知，0表示不升级',
+  `escalate_channel_ids` text COMMENT '升级通知渠道ID(JSON)',
+  `remark` varchar(500) NOT NULL DEFAULT '' COMMENT '备注',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp() COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp() COMMENT '修改时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='通知规则表';
+
+-- 创建静默窗口表
+CREATE TABLE `notification_silence` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `service_id` bigint(20) NOT NULL DEFAULT 0 COMMENT '服务ID，0表示不限',
+  `group_name` varchar(100) NOT NULL DEFAULT '' COMMENT '分组，空表示不限',
+  `tag` varchar(100) NOT NULL DEFAULT '' COMMENT '标签，空表示不限',
+  `event_types` text COMMENT '告警类型(JSON)，为空表示所有类型',
+  `starts_at` datetime NOT NULL COMMENT '开始时间',
+  `ends_at` datetime NOT NULL COMMENT '结束时间',
+  `comment` varchar(500) NOT NULL DEFAULT '' COMMENT '说明',
+  `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp() COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_ends_at` (`ends_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='通知静默窗口表';
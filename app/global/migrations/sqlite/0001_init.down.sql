@@ -0,0 +1,3 @@
+-- 删除所有表，数据将全部丢失
+DROP TABLE IF EXISTS `service_log`;
+DROP TABLE IF EXISTS `service`;
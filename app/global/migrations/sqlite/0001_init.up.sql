@@ -0,0 +1,33 @@
+-- 服务管理工具 SQLite 初始表结构，与 MySQL 的 0001_init 保持一致
+CREATE TABLE IF NOT EXISTS `service` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(255) NOT NULL DEFAULT '',
+  `title` varchar(255) NOT NULL DEFAULT '',
+  `dir` varchar(255) NOT NULL DEFAULT '',
+  `cmd_start` varchar(500) NOT NULL DEFAULT '',
+  `cmd_stop` varchar(500) NOT NULL DEFAULT '',
+  `cmd_restart` varchar(500) NOT NULL DEFAULT '',
+  `port` integer NOT NULL DEFAULT 0,
+  `health_check_url` varchar(500) DEFAULT '',
+  `auto_restart` numeric DEFAULT 0,
+  `max_restart_count` integer DEFAULT 3,
+  `restart_interval` integer DEFAULT 30,
+  `remark` varchar(500) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp,
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp
+);
+
+CREATE TABLE IF NOT EXISTS `service_log` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `service_id` integer NOT NULL,
+  `operation` varchar(50) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `output` text,
+  `error` text,
+  `duration` integer DEFAULT 0,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp
+);
+CREATE INDEX IF NOT EXISTS `idx_service_log_service_id` ON `service_log` (`service_id`);
+CREATE INDEX IF NOT EXISTS `idx_service_log_operation` ON `service_log` (`operation`);
+CREATE INDEX IF NOT EXISTS `idx_service_log_status` ON `service_log` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_service_log_created_at` ON `service_log` (`created_at`);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `uk_service_port`;
+DROP INDEX IF EXISTS `uk_service_name`;
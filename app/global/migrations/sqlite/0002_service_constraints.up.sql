@@ -0,0 +1,4 @@
+-- 服务名称和端口唯一，SQLite 的 varchar 与 text 相同，不需要修改列类型
+-- 已有重复的名称或端口时迁移失败，需要先修改重复的服务
+CREATE UNIQUE INDEX `uk_service_name` ON `service` (`name`);
+CREATE UNIQUE INDEX `uk_service_port` ON `service` (`port`);
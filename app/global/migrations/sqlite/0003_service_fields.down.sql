@@ -0,0 +1,7 @@
+ALTER TABLE `service` DROP COLUMN `liveness_probe`;
+ALTER TABLE `service` DROP COLUMN `readiness_probe`;
+ALTER TABLE `service` DROP COLUMN `startup_probe`;
+ALTER TABLE `service` DROP COLUMN `tags`;
+ALTER TABLE `service` DROP COLUMN `group_name`;
+ALTER TABLE `service` DROP COLUMN `run_mode`;
+ALTER TABLE `service` DROP COLUMN `retention_days`;
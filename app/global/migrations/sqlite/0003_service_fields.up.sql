@@ -0,0 +1,8 @@
+-- 服务的日志保留天数、运行模式、分组、标签和探针
+ALTER TABLE `service` ADD COLUMN `retention_days` integer NOT NULL DEFAULT 0;
+ALTER TABLE `service` ADD COLUMN `run_mode` varchar(20) NOT NULL DEFAULT 'command';
+ALTER TABLE `service` ADD COLUMN `group_name` varchar(100) NOT NULL DEFAULT '';
+ALTER TABLE `service` ADD COLUMN `tags` varchar(500) NOT NULL DEFAULT '';
+ALTER TABLE `service` ADD COLUMN `startup_probe` text;
+ALTER TABLE `service` ADD COLUMN `readiness_probe` text;
+ALTER TABLE `service` ADD COLUMN `liveness_probe` text;
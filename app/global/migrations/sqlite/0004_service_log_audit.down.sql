@@ -0,0 +1,9 @@
+-- SQLite 不能删除有索引的列，先删除索引
+DROP INDEX IF EXISTS `idx_service_log_batch_id`;
+DROP INDEX IF EXISTS `idx_service_log_request_id`;
+DROP INDEX IF EXISTS `idx_service_log_actor`;
+ALTER TABLE `service_log` DROP COLUMN `batch_id`;
+ALTER TABLE `service_log` DROP COLUMN `trigger_type`;
+ALTER TABLE `service_log` DROP COLUMN `request_id`;
+ALTER TABLE `service_log` DROP COLUMN `client_ip`;
+ALTER TABLE `service_log` DROP COLUMN `actor`;
@@ -0,0 +1,9 @@
+-- 操作日志记录操作人、来源IP、请求ID、触发方式和批次
+ALTER TABLE `service_log` ADD COLUMN `actor` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE `service_log` ADD COLUMN `client_ip` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE `service_log` ADD COLUMN `request_id` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE `service_log` ADD COLUMN `trigger_type` varchar(20) NOT NULL DEFAULT 'system';
+ALTER TABLE `service_log` ADD COLUMN `batch_id` varchar(64) NOT NULL DEFAULT '';
+CREATE INDEX `idx_service_log_actor` ON `service_log` (`actor`);
+CREATE INDEX `idx_service_log_request_id` ON `service_log` (`request_id`);
+CREATE INDEX `idx_service_log_batch_id` ON `service_log` (`batch_id`);
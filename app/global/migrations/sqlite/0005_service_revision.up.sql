@@ -0,0 +1,14 @@
+-- 服务配置的历史版本
+CREATE TABLE `service_revision` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `service_id` integer NOT NULL,
+  `revision` integer NOT NULL,
+  `action` varchar(20) NOT NULL,
+  `snapshot` text,
+  `changed_fields` text,
+  `author` varchar(64) NOT NULL DEFAULT '',
+  `request_id` varchar(64) NOT NULL DEFAULT '',
+  `comment` varchar(500) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp
+);
+CREATE UNIQUE INDEX `idx_service_revision` ON `service_revision` (`service_id`, `revision`);
@@ -0,0 +1,15 @@
+-- 操作日志清理记录
+CREATE TABLE `log_retention_run` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `trigger_type` varchar(20) NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `retention_days` integer NOT NULL DEFAULT 0,
+  `keep_last` integer NOT NULL DEFAULT 0,
+  `deleted_count` integer NOT NULL DEFAULT 0,
+  `archived_count` integer NOT NULL DEFAULT 0,
+  `archive_file` varchar(500) NOT NULL DEFAULT '',
+  `detail` text,
+  `error` text,
+  `started_at` datetime NOT NULL,
+  `finished_at` datetime DEFAULT NULL
+);
@@ -0,0 +1,24 @@
+-- 用户和按服务、分组的授权
+CREATE TABLE `user` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `username` varchar(64) NOT NULL,
+  `password` varchar(255) NOT NULL,
+  `role` varchar(20) NOT NULL DEFAULT '',
+  `token_version` integer NOT NULL DEFAULT 0,
+  `enabled` numeric NOT NULL DEFAULT 1,
+  `last_login_at` datetime DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT current_timestamp,
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp
+);
+CREATE UNIQUE INDEX `uk_user_username` ON `user` (`username`);
+
+CREATE TABLE `user_permission` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer NOT NULL,
+  `role` varchar(20) NOT NULL,
+  `service_id` integer NOT NULL DEFAULT 0,
+  `group_name` varchar(100) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp
+);
+CREATE INDEX `idx_user_permission_user_id` ON `user_permission` (`user_id`);
+CREATE INDEX `idx_user_permission_service_id` ON `user_permission` (`service_id`);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `notification_silence`;
+DROP TABLE IF EXISTS `notification_rule`;
+DROP TABLE IF EXISTS `notification_channel`;
@@ -0,0 +1,46 @@
+-- 告警通知的渠道、规则和静默窗口
+CREATE TABLE `notification_channel` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(100) NOT NULL,
+  `type` varchar(20) NOT NULL DEFAULT 'auto',
+  `url` varchar(500) NOT NULL,
+  `secret` varchar(255) NOT NULL DEFAULT '',
+  `enabled` numeric NOT NULL DEFAULT 1,
+  `remark` varchar(500) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp,
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp
+);
+CREATE UNIQUE INDEX `uk_notification_channel_name` ON `notification_channel` (`name`);
+
+CREATE TABLE `notification_rule` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `name` varchar(100) NOT NULL,
+  `enabled` numeric NOT NULL DEFAULT 1,
+  `service_ids` text,
+  `group_names` text,
+  `tags` text,
+  `event_types` text,
+  `min_level` varchar(20) NOT NULL DEFAULT '',
+  `channel_ids` text,
+  `repeat_interval` integer NOT NULL DEFAULT 0,
+  `send_resolved` numeric NOT NULL DEFAULT 1,
+  `escalate_after` integer NOT NULL DEFAULT 0,
+  `escalate_channel_ids` text,
+  `remark` varchar(500) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp,
+  `updated_at` datetime NOT NULL DEFAULT current_timestamp
+);
+
+CREATE TABLE `notification_silence` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `service_id` integer NOT NULL DEFAULT 0,
+  `group_name` varchar(100) NOT NULL DEFAULT '',
+  `tag` varchar(100) NOT NULL DEFAULT '',
+  `event_types` text,
+  `starts_at` datetime NOT NULL,
+  `ends_at` datetime NOT NULL,
+  `comment` varchar(500) NOT NULL DEFAULT '',
+  `created_by` varchar(64) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT current_timestamp
+);
+CREATE INDEX `idx_notification_silence_ends_at` ON `notification_silence` (`ends_at`);
@@ -0,0 +1,27 @@
+package global
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// InitSqliteClient 打开 SQLite 数据库，文件不存在时创建，表结构由迁移创建
+// 使用 WAL 模式，写入冲突时最多等待5秒，事务开始时即获取写锁，避免多个连接同时写入时失败
+func InitSqliteClient(path string) (*gorm.DB, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("创建数据库目录失败: %v", err)
+		}
+	}
+
+	dsn := path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
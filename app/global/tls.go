@@ -0,0 +1,203 @@
+package global
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"go_service/app/config"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// certReloadDelay 证书文件变化后延迟加载，等待证书和私钥都写入完成
+const certReloadDelay = 500 * time.Millisecond
+
+// TLSReloader 证书热加载
+// 监听证书所在目录，文件变化时重新加载证书、私钥和客户端CA，加载失败时继续使用原证书，
+// 新的握手立即使用新证书，已建立的连接不受影响
+type TLSReloader struct {
+	mutex      sync.RWMutex
+	security   config.SecurityConfig
+	cert       *tls.Certificate
+	clientCAs  *x509.CertPool
+	clientAuth tls.ClientAuthType
+
+	watcher *fsnotify.Watcher
+	dirs    map[string]bool
+	timer   *time.Timer
+}
+
+// NewTLSReloader 加载证书并开始监听证书文件，配置中的证书路径变更时同样重新加载
+func NewTLSReloader(security config.SecurityConfig) (*TLSReloader, error) {
+	r := &TLSReloader{dirs: make(map[string]bool)}
+	if err := r.load(security); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("监听证书文件失败: %v", err)
+	}
+	r.watcher = watcher
+	r.watch(security)
+	go r.run()
+
+	config.OnChange(func(oldConfig, newConfig *config.Config) {
+		oldSecurity, newSecurity := oldConfig.Security, newConfig.Security
+		if oldSecurity.CertFile == newSecurity.CertFile && oldSecurity.KeyFile == newSecurity.KeyFile &&
+			oldSecurity.ClientCAFile == newSecurity.ClientCAFile && oldSecurity.ClientAuth == newSecurity.ClientAuth {
+			return
+		}
+		if err := r.load(newSecurity); err != nil {
+			log.Printf("重新加载证书失败，继续使用原证书: %v", err)
+			return
+		}
+		r.watch(newSecurity)
+		log.Printf("证书配置已更新")
+	})
+	return r, nil
+}
+
+// TLSConfig 服务器使用的TLS配置，每次握手读取当前证书
+func (r *TLSReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetCertificate:     r.getCertificate,
+		GetConfigForClient: r.getConfigForClient,
+	}
+}
+
+// getCertificate 当前服务器证书
+func (r *TLSReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.cert, nil
+}
+
+// getConfigForClient 按当前的客户端CA和认证方式生成握手配置
+func (r *TLSReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		ClientCAs:    r.clientCAs,
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}, nil
+}
+
+// load 加载证书、私钥和客户端CA，全部成功后才替换
+func (r *TLSReloader) load(security config.SecurityConfig) error {
+	cert, err := tls.LoadX509KeyPair(security.CertFile, security.KeyFile)
+	if err != nil {
+		return fmt.Errorf("加载证书失败: %v", err)
+	}
+
+	clientAuth := tls.NoClientCert
+	var clientCAs *x509.CertPool
+	switch security.ClientAuth {
+	case config.ClientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case config.ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	if clientAuth != tls.NoClientCert {
+		pem, err := os.ReadFile(security.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端CA失败: %v", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("客户端CA文件中没有有效的证书: %s", security.ClientCAFile)
+		}
+	}
+
+	r.mutex.Lock()
+	r.security = security
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.clientAuth = clientAuth
+	r.mutex.Unlock()
+	return nil
+}
+
+// watch 监听证书文件所在的目录，证书通常通过替换文件或软链接更新，直接监听文件会在替换后失效
+func (r *TLSReloader) watch(security config.SecurityConfig) {
+	for _, file := range []string{security.CertFile, security.KeyFile, security.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if r.dirs[dir] {
+			continue
+		}
+		if err := r.watcher.Add(dir); err != nil {
+			log.Printf("监听证书目录 %s 失败: %v", dir, err)
+			continue
+		}
+		r.dirs[dir] = true
+	}
+}
+
+// run 处理文件变化事件，短时间内的多次变化只加载一次
+func (r *TLSReloader) run() {
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if !r.isCertFile(event.Name) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			r.mutex.Lock()
+			if r.timer != nil {
+				r.timer.Stop()
+			}
+			r.timer = time.AfterFunc(certReloadDelay, r.reload)
+			r.mutex.Unlock()
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("监听证书文件出错: %v", err)
+		}
+	}
+}
+
+// isCertFile 是否为当前使用的证书、私钥或CA文件
+func (r *TLSReloader) isCertFile(name string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	name = filepath.Clean(name)
+	for _, file := range []string{r.security.CertFile, r.security.KeyFile, r.security.ClientCAFile} {
+		if file != "" && filepath.Clean(file) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reload 按当前配置重新加载证书
+func (r *TLSReloader) reload() {
+	r.mutex.RLock()
+	security := r.security
+	r.mutex.RUnlock()
+
+	if err := r.load(security); err != nil {
+		log.Printf("重新加载证书失败，继续使用原证书: %v", err)
+		return
+	}
+	log.Printf("证书已重新加载: %s", security.CertFile)
+}
+
+// Close 停止监听证书文件
+func (r *TLSReloader) Close() error {
+	return r.watcher.Close()
+}
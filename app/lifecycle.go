@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"go_service/app/config"
+	"go_service/app/service"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout 未配置 server.shutdown_timeout 时的退出等待时间
+const defaultShutdownTimeout = 30 * time.Second
+
+// worker 退出时需要停止的后台任务，Stop 等待进行中的任务结束
+type worker interface {
+	Stop()
+}
+
+// run 启动服务器并处理信号：SIGHUP 重新加载配置，SIGINT、SIGTERM 优雅退出，退出过程中再次收到信号时立即退出
+func run(srv *server, workers []worker) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.listen()
+	}()
+
+	for {
+		select {
+		case err := <-errChan:
+			log.Printf("服务器启动失败: %v", err)
+			shutdown(srv, workers)
+			os.Exit(1)
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				log.Printf("收到 SIGHUP，重新加载配置")
+				if err := config.ReloadConfig(); err != nil {
+					log.Printf("重新加载配置失败: %v", err)
+				}
+				continue
+			}
+
+			log.Printf("收到信号 %s，开始退出", sig)
+			go func() {
+				for sig := range signals {
+					if sig != syscall.SIGHUP {
+						log.Printf("再次收到信号 %s，立即退出", sig)
+						os.Exit(1)
+					}
+				}
+			}()
+			shutdown(srv, workers)
+			return
+		}
+	}
+}
+
+// shutdown 按顺序退出：停止接受请求并等待进行中的请求，停止后台任务，等待进行中的服务操作，
+// 最后写入缓冲的操作日志并关闭输出日志。前三步共用 server.shutdown_timeout 期限，日志始终写入
+func shutdown(srv *server, workers []worker) {
+	timeout := config.GlobalConfig.Server.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.shutdown(ctx); err != nil {
+		log.Printf("等待请求完成超时: %v", err)
+	}
+
+	for _, w := range workers {
+		if err := waitDone(ctx, w.Stop); err != nil {
+			log.Printf("等待后台任务停止超时: %v", err)
+			break
+		}
+	}
+
+	if err := service.WaitOperations(ctx); err != nil {
+		log.Printf("等待服务操作完成超时: %v", err)
+	}
+
+	service.CloseLogServices()
+	service.CloseOutputLogs()
+	log.Printf("服务管理工具已退出")
+}
+
+// waitDone 执行 fn 直到完成或超过 ctx 的期限
+func waitDone(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
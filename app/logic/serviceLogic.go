@@ -67,12 +67,12 @@ func (l Logic) FindAll(ctx context.Context) ([]model.ServiceStatusModel, error)
 		var pid string
 		var process string
 		// 根据port查询服务是否启动
-		port := strconv.Itoa(int(v.Port))
-		var tmp = portList[port]
-		if tmp != nil {
+		if listener, ok := portList[v.Port]; ok {
 			status = 1
-			pid = tmp["pid"].(string)
-			process = tmp["process"].(string)
+			if listener.Pid > 0 {
+				pid = strconv.Itoa(listener.Pid)
+			}
+			process = listener.Process
 		}
 		infoNew = append(infoNew, model.ServiceStatusModel{ServiceModel: v, Status: status, Pid: pid, Process: process})
 	}
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"go_service/app/common"
+	"go_service/app/config"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientIPKey 上下文中保存客户端IP的键
+const clientIPKey = "client_ip"
+
+// deniedLogInterval 同一IP被拒绝时的日志间隔，避免扫描请求刷屏
+const deniedLogInterval = time.Minute
+
+// ipRules 解析后的IP白名单和可信代理，配置变更时整体替换
+type ipRules struct {
+	allowed []netip.Prefix
+	proxies []netip.Prefix
+}
+
+// deniedEntry 被拒绝IP的日志节流记录
+type deniedEntry struct {
+	lastLog time.Time
+	count   int
+}
+
+var (
+	ipRulesOnce sync.Once
+	ipRulesLock sync.RWMutex
+	currentIPs  = &ipRules{}
+
+	deniedLock sync.Mutex
+	deniedIPs  = make(map[string]*deniedEntry)
+)
+
+// buildIPRules 解析安全配置中的IP规则，配置已校验过，解析失败的列表视为空
+func buildIPRules(security config.SecurityConfig) *ipRules {
+	allowed, err := config.ParseIPPrefixes(security.AllowedIPs)
+	if err != nil {
+		log.Printf("解析 allowed_ips 失败: %v", err)
+	}
+	proxies, err := config.ParseIPPrefixes(security.TrustedProxies)
+	if err != nil {
+		log.Printf("解析 trusted_proxies 失败: %v", err)
+	}
+	return &ipRules{allowed: allowed, proxies: proxies}
+}
+
+// initIPRules 加载IP规则并注册配置变更回调
+func initIPRules() {
+	ipRulesOnce.Do(func() {
+		ipRulesLock.Lock()
+		currentIPs = buildIPRules(config.GlobalConfig.Security)
+		ipRulesLock.Unlock()
+
+		config.OnChange(func(oldConfig, newConfig *config.Config) {
+			rules := buildIPRules(newConfig.Security)
+			ipRulesLock.Lock()
+			currentIPs = rules
+			ipRulesLock.Unlock()
+			log.Printf("IP白名单已更新: %d 条规则, %d 个可信代理", len(rules.allowed), len(rules.proxies))
+		})
+	})
+}
+
+// loadIPRules 当前生效的IP规则
+func loadIPRules() *ipRules {
+	ipRulesLock.RLock()
+	defer ipRulesLock.RUnlock()
+	return currentIPs
+}
+
+// containsIP IP是否在任一网段内
+func containsIP(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP 解析IP，IPv4映射的IPv6地址转换为IPv4
+func parseIP(value string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.WithZone("").Unmap(), true
+}
+
+// resolveClientIP 解析客户端真实IP
+// 只有直连地址属于可信代理时才读取 X-Forwarded-For，从右向左跳过可信代理，第一个非代理地址即为客户端
+func resolveClientIP(c *gin.Context, rules *ipRules) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		host = c.Request.RemoteAddr
+	}
+	remote, ok := parseIP(host)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	if !containsIP(rules.proxies, remote) {
+		return remote, true
+	}
+
+	forwarded := strings.Split(strings.Join(c.Request.Header.Values("X-Forwarded-For"), ","), ",")
+	client := remote
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if strings.TrimSpace(forwarded[i]) == "" {
+			continue
+		}
+		addr, ok := parseIP(forwarded[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !containsIP(rules.proxies, addr) {
+			break
+		}
+	}
+	return client, true
+}
+
+// ClientIP 客户端真实IP，按可信代理配置处理 X-Forwarded-For，结果缓存在上下文中
+func ClientIP(c *gin.Context) string {
+	if value, ok := c.Get(clientIPKey); ok {
+		return value.(string)
+	}
+
+	initIPRules()
+	ip := c.Request.RemoteAddr
+	if addr, ok := resolveClientIP(c, loadIPRules()); ok {
+		ip = addr.String()
+	}
+	c.Set(clientIPKey, ip)
+	return ip
+}
+
+// IPAllowlist 按 security.allowed_ips 限制访问来源，支持单个IP和CIDR网段，列表为空时不限制
+func IPAllowlist() gin.HandlerFunc {
+	initIPRules()
+	return func(c *gin.Context) {
+		rules := loadIPRules()
+		if len(rules.allowed) == 0 {
+			c.Next()
+			return
+		}
+
+		ip := ClientIP(c)
+		if addr, ok := parseIP(ip); ok && containsIP(rules.allowed, addr) {
+			c.Next()
+			return
+		}
+
+		logDenied(c, ip)
+		c.AbortWithStatusJSON(http.StatusForbidden, common.Response{
+			Code: common.ErrCodePermissionDenied,
+			Msg:  "IP不在允许列表中",
+		})
+	}
+}
+
+// logDenied 记录被拒绝的访问，同一IP每分钟最多记录一次并附带期间被拒绝的次数
+func logDenied(c *gin.Context, ip string) {
+	now := time.Now()
+
+	deniedLock.Lock()
+	entry, ok := deniedIPs[ip]
+	if !ok {
+		entry = &deniedEntry{}
+		deniedIPs[ip] = entry
+	}
+	entry.count++
+	if !entry.lastLog.IsZero() && now.Sub(entry.lastLog) < deniedLogInterval {
+		deniedLock.Unlock()
+		return
+	}
+	count := entry.count
+	entry.count = 0
+	entry.lastLog = now
+	for key, item := range deniedIPs {
+		if now.Sub(item.lastLog) > deniedLogInterval && item.count == 0 {
+			delete(deniedIPs, key)
+		}
+	}
+	deniedLock.Unlock()
+
+	log.Printf("拒绝访问: IP %s 不在允许列表中 (remote=%s, %s %s, X-Forwarded-For=%q, 次数=%d)",
+		ip, c.Request.RemoteAddr, c.Request.Method, c.Request.URL.Path, c.GetHeader("X-Forwarded-For"), count)
+}
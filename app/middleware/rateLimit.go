@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"go_service/app/common"
+	"go_service/app/config"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// 限流桶的清理参数
+const (
+	limiterCleanupInterval = time.Minute
+	limiterIdleTimeout     = 10 * time.Minute
+)
+
+// limiterEntry 单个客户端的令牌桶
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter 按键(IP 或用户)划分的令牌桶集合
+type rateLimiter struct {
+	mutex   sync.Mutex
+	limit   rate.Limit
+	burst   int
+	entries map[string]*limiterEntry
+}
+
+func newRateLimiter(limit rate.Limit, burst int) *rateLimiter {
+	limiter := &rateLimiter{
+		limit:   limit,
+		burst:   burst,
+		entries: make(map[string]*limiterEntry),
+	}
+	go limiter.cleanup()
+	return limiter
+}
+
+// allow 消耗一个令牌，令牌不足时返回需要等待的时间
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mutex.Lock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.entries[key] = entry
+	}
+	entry.lastSeen = time.Now()
+	l.mutex.Unlock()
+
+	reservation := entry.limiter.Reserve()
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+// update 修改速率，已有的令牌桶立即生效
+func (l *rateLimiter) update(limit rate.Limit, burst int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.limit == limit && l.burst == burst {
+		return
+	}
+	l.limit, l.burst = limit, burst
+	for _, entry := range l.entries {
+		entry.limiter.SetLimit(limit)
+		entry.limiter.SetBurst(burst)
+	}
+}
+
+// cleanup 定期清理长时间未访问的令牌桶
+func (l *rateLimiter) cleanup() {
+	ticker := time.NewTicker(limiterCleanupInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.mutex.Lock()
+		for key, entry := range l.entries {
+			if time.Since(entry.lastSeen) > limiterIdleTimeout {
+				delete(l.entries, key)
+			}
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// 全局限流器：请求限流和命令接口限流，配置变更时更新速率
+var (
+	limiterOnce    sync.Once
+	requestLimiter *rateLimiter
+	commandLimiter *rateLimiter
+)
+
+// requestRate 请求限流的速率，突发数不小于每秒请求数
+func requestRate(security config.SecurityConfig) (rate.Limit, int) {
+	burst := security.RateLimitBurst
+	if burst < security.RateLimitRPS {
+		burst = security.RateLimitRPS
+	}
+	return rate.Limit(security.RateLimitRPS), burst
+}
+
+// commandRate 命令接口限流的速率，突发数至少为1
+func commandRate(security config.SecurityConfig) (rate.Limit, int) {
+	burst := security.CmdRateLimitBurst
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.Limit(security.CmdRateLimitRPS), burst
+}
+
+// initLimiters 创建限流器并注册配置变更回调
+func initLimiters() {
+	limiterOnce.Do(func() {
+		security := config.GlobalConfig.Security
+		requestLimiter = newRateLimiter(requestRate(security))
+		commandLimiter = newRateLimiter(commandRate(security))
+
+		config.OnChange(func(oldConfig, newConfig *config.Config) {
+			requestLimiter.update(requestRate(newConfig.Security))
+			commandLimiter.update(commandRate(newConfig.Security))
+		})
+	})
+}
+
+// RateLimit 按客户端IP限流，在认证之前执行，避免未认证的请求消耗资源
+func RateLimit() gin.HandlerFunc {
+	initLimiters()
+	return func(c *gin.Context) {
+		if !config.GlobalConfig.Security.RateLimitEnabled {
+			c.Next()
+			return
+		}
+		if ok, delay := requestLimiter.allow("ip:" + ClientIP(c)); !ok {
+			abortTooManyRequests(c, delay)
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalRateLimit 按登录用户限流，同一用户从多个IP访问时共用额度，需在 Auth 之后执行
+func PrincipalRateLimit() gin.HandlerFunc {
+	initLimiters()
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil || !config.GlobalConfig.Security.RateLimitEnabled {
+			c.Next()
+			return
+		}
+		if ok, delay := requestLimiter.allow("user:" + strconv.FormatInt(user.Id, 10)); !ok {
+			abortTooManyRequests(c, delay)
+			return
+		}
+		c.Next()
+	}
+}
+
+// CommandRateLimit 启动、停止等命令接口的独立限流，按登录用户计算，未开启认证时按IP计算
+func CommandRateLimit() gin.HandlerFunc {
+	initLimiters()
+	return func(c *gin.Context) {
+		if !config.GlobalConfig.Security.RateLimitEnabled {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + ClientIP(c)
+		if user := CurrentUser(c); user != nil {
+			key = "user:" + strconv.FormatInt(user.Id, 10)
+		}
+		if ok, delay := commandLimiter.allow(key); !ok {
+			abortTooManyRequests(c, delay)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortTooManyRequests 返回 429，Retry-After 为需要等待的秒数
+func abortTooManyRequests(c *gin.Context, delay time.Duration) {
+	retryAfter := int(math.Ceil(delay.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, common.Response{
+		Code: common.ErrCodeTooManyRequests,
+		Msg:  common.ErrTooManyRequests.Message,
+		Data: gin.H{"retry_after": retryAfter},
+	})
+}
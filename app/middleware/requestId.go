@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"go_service/app/model"
+	"go_service/app/service"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIdHeader 请求ID的请求头和响应头
+const requestIdHeader = "X-Request-Id"
+
+// requestIdKey 上下文中保存请求ID的键
+const requestIdKey = "request_id"
+
+// requestIdPattern 沿用客户端传入的请求ID时的格式限制
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestId 为每个请求分配请求ID，客户端或网关传入有效的 X-Request-Id 时沿用，并在响应头中返回
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(requestIdHeader)
+		if !requestIdPattern.MatchString(requestId) {
+			requestId = service.NewTraceId()
+		}
+		c.Set(requestIdKey, requestId)
+		c.Header(requestIdHeader, requestId)
+		c.Next()
+	}
+}
+
+// OperationSource 将操作人、来源IP和请求ID写入请求上下文，服务操作记录到操作日志中，需在 Auth 之后使用
+// 来源IP与访问控制、限流相同，按可信代理配置解析
+func OperationSource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source := model.OperationSource{
+			ClientIp:  ClientIP(c),
+			RequestId: c.GetString(requestIdKey),
+			Trigger:   model.TriggerApi,
+		}
+		if user := CurrentUser(c); user != nil {
+			source.Actor = user.Username
+		}
+		c.Request = c.Request.WithContext(service.WithOperationSource(c.Request.Context(), source))
+		c.Next()
+	}
+}
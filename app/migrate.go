@@ -0,0 +1,83 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"go_service/app/config"
+	"go_service/app/global"
+	"os"
+)
+
+// RunMigrate 查看或执行数据库迁移，服务启动时也会自动执行未执行的迁移
+//
+//	go_service migrate status [-c config.yml] [-db default]
+//	go_service migrate up [-c config.yml] [-db default]
+//	go_service migrate down [-c config.yml] [-db default] [-n 1]
+func RunMigrate(args []string) {
+	if len(args) == 0 || (args[0] != "status" && args[0] != "up" && args[0] != "down") {
+		fmt.Fprintln(os.Stderr, "用法: go_service migrate status|up|down [-c config.yml] [-db default] [-n 1]")
+		os.Exit(2)
+	}
+	action := args[0]
+
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configFile := flags.String("c", "config.yml", "specify config file")
+	name := flags.String("db", "default", "数据库在 database 中的名称")
+	steps := flags.Int("n", 1, "down 回滚的迁移个数")
+	flags.Parse(args[1:])
+
+	if err := config.InitConfig(*configFile); err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.GetDatabaseConfig(*name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	db, err := global.OpenDatabase(*cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开数据库 %s 失败: %v\n", *name, err)
+		os.Exit(1)
+	}
+
+	switch action {
+	case "status":
+		statuses, err := global.MigrationStatuses(db, cfg.Driver)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for _, status := range statuses {
+			state := "未执行"
+			if status.AppliedAt != nil {
+				state = "已执行 " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %s\n", status.Version, status.Name, state)
+		}
+	case "up":
+		migrations, err := global.MigrateUp(db, cfg.Driver)
+		for _, migration := range migrations {
+			fmt.Printf("已执行 %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("没有未执行的迁移")
+		}
+	case "down":
+		migrations, err := global.MigrateDown(db, cfg.Driver, *steps)
+		for _, migration := range migrations {
+			fmt.Printf("已回滚 %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// 告警类型
+const (
+	AlertServiceDown      = "service_down"      // 服务异常停止
+	AlertHealthFailed     = "health_failed"     // 连续健康检查失败达到阈值
+	AlertHealthRecovered  = "health_recovered"  // 健康检查恢复
+	AlertServiceRecovered = "service_recovered" // 服务启动或重启成功
+	AlertRestartFailed    = "restart_failed"    // 自动重启失败
+	AlertRestartExhausted = "restart_exhausted" // 达到最大重启次数，停止自动重启
+	AlertOperationFailed  = "operation_failed"  // 启动、停止等操作失败
+)
+
+// 告警级别
+const (
+	AlertLevelCritical = "critical"
+	AlertLevelWarning  = "warning"
+	AlertLevelInfo     = "info"
+)
+
+// alertLevels 告警级别等级，未知级别为0
+var alertLevels = map[string]int{
+	AlertLevelInfo:     1,
+	AlertLevelWarning:  2,
+	AlertLevelCritical: 3,
+}
+
+// AlertLevelRank 告警级别等级，用于比较严重程度
+func AlertLevelRank(level string) int {
+	return alertLevels[level]
+}
+
+// firingAlertTypes 表示出现问题的告警类型，通知规则按这些类型匹配
+var firingAlertTypes = map[string]bool{
+	AlertServiceDown:      true,
+	AlertHealthFailed:     true,
+	AlertRestartFailed:    true,
+	AlertRestartExhausted: true,
+	AlertOperationFailed:  true,
+}
+
+// IsFiringAlertType 是否为表示出现问题的告警类型
+func IsFiringAlertType(alertType string) bool {
+	return firingAlertTypes[alertType]
+}
+
+// AlertCondition 告警对应的问题，返回问题标识以及该告警是触发还是恢复
+// 服务停止、自动重启失败属于同一问题，由服务启动成功恢复；健康检查失败由健康检查恢复或服务重新启动恢复
+// 操作失败不跟踪恢复，返回空标识
+func AlertCondition(event AlertEvent) (keys []string, resolving bool) {
+	down := fmt.Sprintf("down:%d", event.ServiceId)
+	health := fmt.Sprintf("health:%d", event.ServiceId)
+	switch event.Type {
+	case AlertServiceDown, AlertRestartFailed, AlertRestartExhausted:
+		return []string{down}, false
+	case AlertHealthFailed:
+		return []string{health}, false
+	case AlertServiceRecovered:
+		return []string{down, health}, true
+	case AlertHealthRecovered:
+		return []string{health}, true
+	}
+	return nil, false
+}
+
+// 告警 webhook 的消息格式
+const (
+	AlertFormatAuto     = "auto" // 按 webhook 地址识别，无法识别时使用 json
+	AlertFormatJSON     = "json"
+	AlertFormatDingTalk = "dingtalk"
+	AlertFormatWeCom    = "wecom"
+	AlertFormatFeishu   = "feishu"
+	AlertFormatSlack    = "slack"
+)
+
+// AlertEvent 告警事件，json 格式的 webhook 直接发送该结构
+type AlertEvent struct {
+	Type        string    `json:"type"`
+	Level       string    `json:"level"`
+	ServiceId   int64     `json:"service_id"`
+	ServiceName string    `json:"service_name"`
+	Group       string    `json:"group"`
+	Tags        []string  `json:"tags,omitempty"`
+	Message     string    `json:"message"`
+	Host        string    `json:"host"`                // go_service 所在主机
+	Escalated   bool      `json:"escalated,omitempty"` // 问题持续未恢复时的升级通知
+	Time        time.Time `json:"time"`
+}
+
+// AlertIncidentModel 未恢复的问题
+type AlertIncidentModel struct {
+	Key         string    `json:"key"`
+	Type        string    `json:"type"` // 最近一次触发的告警类型
+	Level       string    `json:"level"`
+	ServiceId   int64     `json:"service_id"`
+	ServiceName string    `json:"service_name"`
+	Group       string    `json:"group"`
+	Message     string    `json:"message"`
+	StartedAt   time.Time `json:"started_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	RuleIds     []int64   `json:"rule_ids"`     // 已通知的规则，0 为默认 webhook
+	EscalatedTo []int64   `json:"escalated_to"` // 已升级通知的规则
+}
+
+// AlertTitle 告警类型的中文名称
+func AlertTitle(alertType string) string {
+	switch alertType {
+	case AlertServiceDown:
+		return "服务异常停止"
+	case AlertHealthFailed:
+		return "健康检查失败"
+	case AlertHealthRecovered:
+		return "健康检查恢复"
+	case AlertServiceRecovered:
+		return "服务恢复"
+	case AlertRestartFailed:
+		return "自动重启失败"
+	case AlertRestartExhausted:
+		return "停止自动重启"
+	case AlertOperationFailed:
+		return "服务操作失败"
+	}
+	return alertType
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// 健康检查状态
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// LivenessModel 存活检查结果，进程能响应请求即为存活
+type LivenessModel struct {
+	Status    string    `json:"status"`
+	Version   string    `json:"version"`
+	StartedAt time.Time `json:"started_at"`
+	Uptime    int64     `json:"uptime"` // 运行时长(秒)
+}
+
+// DependencyCheckModel 单项依赖检查结果
+type DependencyCheckModel struct {
+	Status  string                 `json:"status"`
+	Latency int64                  `json:"latency"` // 检查耗时(毫秒)
+	Error   string                 `json:"error,omitempty"`
+	Detail  map[string]interface{} `json:"detail,omitempty"`
+}
+
+// ReadinessModel 就绪检查结果，任一依赖检查失败时为 down
+type ReadinessModel struct {
+	Status    string                           `json:"status"`
+	Checks    map[string]*DependencyCheckModel `json:"checks"`             // database, log_queue, port_scan
+	Services  map[string]interface{}           `json:"services,omitempty"` // 服务数量统计
+	CheckedAt time.Time                        `json:"checked_at"`
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// NotificationChannelModel 通知渠道，即一个 webhook 地址
+type NotificationChannelModel struct {
+	Id        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Type      string    `json:"type" gorm:"type:varchar(20);default:auto"` // 消息格式: auto, json, dingtalk, wecom, feishu, slack
+	Url       string    `json:"url" gorm:"type:varchar(500);not null"`
+	Secret    string    `json:"secret" gorm:"type:varchar(255)"` // 钉钉、飞书机器人的加签密钥
+	Enabled   bool      `json:"enabled"`
+	Remark    string    `json:"remark" gorm:"type:varchar(500)"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (c NotificationChannelModel) TableName() string {
+	return "notification_channel"
+}
+
+// Validate 校验通知渠道
+func (c *NotificationChannelModel) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("渠道名称不能为空")
+	}
+	switch c.Type {
+	case "":
+		c.Type = AlertFormatAuto
+	case AlertFormatAuto, AlertFormatJSON, AlertFormatDingTalk, AlertFormatWeCom, AlertFormatFeishu, AlertFormatSlack:
+	default:
+		return fmt.Errorf("不支持的渠道类型: %s, 可选值: auto, json, dingtalk, wecom, feishu, slack", c.Type)
+	}
+	parsed, err := url.Parse(c.Url)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("无效的webhook地址: %s", c.Url)
+	}
+	return nil
+}
+
+// NotificationRuleModel 通知规则，匹配的告警发送到指定渠道
+// 服务ID、分组、标签之间为或的关系，都为空时匹配所有服务
+type NotificationRuleModel struct {
+	Id                 int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name               string    `json:"name" gorm:"type:varchar(100);not null"`
+	Enabled            bool      `json:"enabled"`
+	ServiceIds         []int64   `json:"service_ids" gorm:"type:text;serializer:json"`
+	Groups             []string  `json:"groups" gorm:"column:group_names;type:text;serializer:json"`
+	Tags               []string  `json:"tags" gorm:"type:text;serializer:json"`
+	EventTypes         []string  `json:"event_types" gorm:"type:text;serializer:json"`          // 为空表示所有告警类型
+	MinLevel           string    `json:"min_level" gorm:"type:varchar(20)"`                     // 最低告警级别，空表示不限
+	ChannelIds         []int64   `json:"channel_ids" gorm:"type:text;serializer:json"`          // 通知渠道
+	RepeatInterval     int       `json:"repeat_interval" gorm:"default:0"`                      // 同一服务同类告警的最小通知间隔(秒)，0 使用 monitor.alert_silence
+	SendResolved       bool      `json:"send_resolved"`                                         // 问题恢复时通知
+	EscalateAfter      int       `json:"escalate_after" gorm:"default:0"`                       // 问题持续多少秒未恢复时通知升级渠道，0 表示不升级
+	EscalateChannelIds []int64   `json:"escalate_channel_ids" gorm:"type:text;serializer:json"` // 升级渠道
+	Remark             string    `json:"remark" gorm:"type:varchar(500)"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (r NotificationRuleModel) TableName() string {
+	return "notification_rule"
+}
+
+// Validate 校验通知规则
+func (r *NotificationRuleModel) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("规则名称不能为空")
+	}
+	for _, eventType := range r.EventTypes {
+		if !IsFiringAlertType(eventType) {
+			return fmt.Errorf("不支持的告警类型: %s, 可选值: service_down, health_failed, restart_failed, restart_exhausted, operation_failed", eventType)
+		}
+	}
+	if r.MinLevel != "" && AlertLevelRank(r.MinLevel) == 0 {
+		return fmt.Errorf("无效的告警级别: %s, 可选值: info, warning, critical", r.MinLevel)
+	}
+	if len(r.ChannelIds) == 0 {
+		return fmt.Errorf("通知渠道不能为空")
+	}
+	if r.RepeatInterval < 0 || r.EscalateAfter < 0 {
+		return fmt.Errorf("repeat_interval 和 escalate_after 不能小于0")
+	}
+	if r.EscalateAfter > 0 && len(r.EscalateChannelIds) == 0 {
+		return fmt.Errorf("设置 escalate_after 时升级渠道不能为空")
+	}
+	return nil
+}
+
+// Matches 规则是否匹配告警，只匹配表示出现问题的告警
+func (r *NotificationRuleModel) Matches(event AlertEvent) bool {
+	if !r.Enabled || !IsFiringAlertType(event.Type) {
+		return false
+	}
+	if len(r.EventTypes) > 0 && !containsString(r.EventTypes, event.Type) {
+		return false
+	}
+	if r.MinLevel != "" && AlertLevelRank(event.Level) < AlertLevelRank(r.MinLevel) {
+		return false
+	}
+	if len(r.ServiceIds) == 0 && len(r.Groups) == 0 && len(r.Tags) == 0 {
+		return true
+	}
+	for _, id := range r.ServiceIds {
+		if id == event.ServiceId {
+			return true
+		}
+	}
+	if event.Group != "" && containsString(r.Groups, event.Group) {
+		return true
+	}
+	for _, tag := range event.Tags {
+		if containsString(r.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationSilenceModel 静默窗口，时间范围内匹配的告警不发送通知
+// 服务ID、分组、标签、告警类型之间为且的关系，为空表示不限
+type NotificationSilenceModel struct {
+	Id         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ServiceId  int64     `json:"service_id" gorm:"default:0"`
+	Group      string    `json:"group" gorm:"column:group_name;type:varchar(100)"`
+	Tag        string    `json:"tag" gorm:"type:varchar(100)"`
+	EventTypes []string  `json:"event_types" gorm:"type:text;serializer:json"`
+	StartsAt   time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt     time.Time `json:"ends_at" gorm:"not null;index"`
+	Comment    string    `json:"comment" gorm:"type:varchar(500)"`
+	CreatedBy  string    `json:"created_by" gorm:"type:varchar(64)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (s NotificationSilenceModel) TableName() string {
+	return "notification_silence"
+}
+
+// Validate 校验静默窗口
+func (s *NotificationSilenceModel) Validate() error {
+	if s.StartsAt.IsZero() {
+		s.StartsAt = time.Now()
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	for _, eventType := range s.EventTypes {
+		if !IsFiringAlertType(eventType) && eventType != AlertHealthRecovered && eventType != AlertServiceRecovered {
+			return fmt.Errorf("不支持的告警类型: %s", eventType)
+		}
+	}
+	return nil
+}
+
+// Matches 静默窗口在 now 时是否生效且匹配告警
+func (s *NotificationSilenceModel) Matches(event AlertEvent, now time.Time) bool {
+	if now.Before(s.StartsAt) || !now.Before(s.EndsAt) {
+		return false
+	}
+	if s.ServiceId > 0 && s.ServiceId != event.ServiceId {
+		return false
+	}
+	if s.Group != "" && s.Group != event.Group {
+		return false
+	}
+	if s.Tag != "" && !containsString(event.Tags, s.Tag) {
+		return false
+	}
+	return len(s.EventTypes) == 0 || containsString(s.EventTypes, event.Type)
+}
+
+// containsString 切片中是否包含指定字符串
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
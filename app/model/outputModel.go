@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// OutputLineModel 服务输出的一行
+type OutputLineModel struct {
+	Time   time.Time `json:"time"`   // 写入时间，无法解析时为零值
+	Stream string    `json:"stream"` // 来源: stdout, stderr, command, system
+	Text   string    `json:"text"`
+}
+
+// OutputTailModel 按行读取的服务输出
+type OutputTailModel struct {
+	ServiceId int64             `json:"service_id"`
+	File      string            `json:"file"`      // 当前输出文件
+	Lines     []OutputLineModel `json:"lines"`     // 按时间正序
+	Truncated bool              `json:"truncated"` // 是否因行数上限截断
+}
+
+// OutputRangeModel 按字节读取的服务输出
+type OutputRangeModel struct {
+	ServiceId  int64  `json:"service_id"`
+	File       string `json:"file"`
+	Offset     int64  `json:"offset"`
+	Length     int64  `json:"length"`      // 实际读取的字节数
+	NextOffset int64  `json:"next_offset"` // 下一次读取的起始位置
+	Size       int64  `json:"size"`        // 文件当前大小
+	Content    string `json:"content"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// 角色，权限依次递增
+const (
+	RoleViewer   = "viewer"   // 查看服务和日志
+	RoleOperator = "operator" // 启动、停止、重启
+	RoleAdmin    = "admin"    // 增删改服务、强杀、批量操作、管理用户
+)
+
+// roleLevels 角色等级，未知角色为0
+var roleLevels = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// RoleLevel 角色等级，用于比较权限大小
+func RoleLevel(role string) int {
+	return roleLevels[role]
+}
+
+// ValidateRole 校验角色，allowEmpty 为 true 时允许为空
+func ValidateRole(role string, allowEmpty bool) error {
+	if role == "" && allowEmpty {
+		return nil
+	}
+	if RoleLevel(role) == 0 {
+		return fmt.Errorf("无效的角色: %s, 可选值: viewer, operator, admin", role)
+	}
+	return nil
+}
+
+// UserPermissionModel 用户在指定服务或分组上的角色
+// ServiceId 和 Group 二选一，分别授权单个服务和同一分组下的所有服务
+type UserPermissionModel struct {
+	Id        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserId    int64     `json:"user_id" gorm:"not null;index"`
+	Role      string    `json:"role" gorm:"type:varchar(20);not null"`
+	ServiceId int64     `json:"service_id" gorm:"default:0"`                      // 0 表示不按服务授权
+	Group     string    `json:"group" gorm:"column:group_name;type:varchar(100)"` // 空表示不按分组授权
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (p UserPermissionModel) TableName() string {
+	return "user_permission"
+}
+
+// Validate 校验授权数据
+func (p *UserPermissionModel) Validate() error {
+	if p.UserId <= 0 {
+		return fmt.Errorf("用户ID不能为空")
+	}
+	if err := ValidateRole(p.Role, false); err != nil {
+		return err
+	}
+	if (p.ServiceId > 0) == (p.Group != "") {
+		return fmt.Errorf("service_id 和 group 必须且只能指定一个")
+	}
+	return nil
+}
+
+// ServiceScope 用户可访问的服务范围，为 nil 时表示不限制
+type ServiceScope struct {
+	ServiceIds []int64
+	Groups     []string
+}
+
+// UserRequest 添加或修改用户
+type UserRequest struct {
+	Id       int64  `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password"` // 修改时为空表示不修改
+	Role     string `json:"role"`     // 全局角色，空表示只拥有按服务或分组授予的权限
+	Enabled  *bool  `json:"enabled"`
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// 探针类型
+const (
+	ProbeTypeTCP  = "tcp"  // TCP 端口连接
+	ProbeTypeHTTP = "http" // HTTP 请求
+	ProbeTypeExec = "exec" // 执行命令，根据退出码判断
+	ProbeTypeLog  = "log"  // 输出中出现指定正则
+)
+
+// ProbeConfig 探针配置
+type ProbeConfig struct {
+	Type             string            `json:"type"`              // tcp, http, exec, log
+	Host             string            `json:"host"`              // tcp 主机，默认 127.0.0.1
+	Port             int64             `json:"port"`              // tcp 端口，默认服务端口
+	Url              string            `json:"url"`               // http 请求地址
+	ExpectStatus     int               `json:"expect_status"`     // http 期望状态码，0 表示 2xx/3xx
+	ExpectBody       string            `json:"expect_body"`       // http 响应体需包含的字符串
+	ExpectHeaders    map[string]string `json:"expect_headers"`    // http 响应头需包含的键值
+	Command          string            `json:"command"`           // exec 命令，退出码为0视为成功
+	Pattern          string            `json:"pattern"`           // log 匹配的正则
+	LogFile          string            `json:"log_file"`          // log 匹配的文件，为空时匹配启动命令输出
+	Timeout          int               `json:"timeout"`           // 单次超时(秒)
+	Period           int               `json:"period"`            // 检测间隔(秒)
+	FailureThreshold int               `json:"failure_threshold"` // 连续失败次数阈值
+}
+
+// Validate 验证探针配置
+func (p *ProbeConfig) Validate() error {
+	switch p.Type {
+	case ProbeTypeTCP:
+		if p.Port < 0 || p.Port > 65535 {
+			return fmt.Errorf("tcp探针端口号必须在1-65535之间")
+		}
+	case ProbeTypeHTTP:
+		if p.Url == "" {
+			return fmt.Errorf("http探针地址不能为空")
+		}
+	case ProbeTypeExec:
+		if p.Command == "" {
+			return fmt.Errorf("exec探针命令不能为空")
+		}
+	case ProbeTypeLog:
+		if p.Pattern == "" {
+			return fmt.Errorf("log探针正则不能为空")
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("log探针正则无效: %v", err)
+		}
+	default:
+		return fmt.Errorf("不支持的探针类型: %s", p.Type)
+	}
+	if p.Timeout < 0 || p.Period < 0 || p.FailureThreshold < 0 {
+		return fmt.Errorf("探针超时、间隔和失败阈值不能为负数")
+	}
+	return nil
+}
@@ -6,14 +6,64 @@ import "time"
 type ServiceLog struct {
 	Id        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
 	ServiceId int64     `json:"service_id" gorm:"not null;index"`
-	Operation string    `json:"operation" gorm:"type:varchar(50);not null"` // start, stop, restart, kill
+	Operation string    `json:"operation" gorm:"type:varchar(50);not null"` // start, stop, restart, force_restart, kill, auto_restart, create, update, delete, restore
 	Status    string    `json:"status" gorm:"type:varchar(20);not null"`    // success, failed
 	Output    string    `json:"output" gorm:"type:text"`
 	Error     string    `json:"error" gorm:"type:text"`
-	Duration  int64     `json:"duration" gorm:"default:0"` // 执行时长(毫秒)
+	Duration  int64     `json:"duration" gorm:"default:0"`                                    // 执行时长(毫秒)
+	Actor     string    `json:"actor" gorm:"type:varchar(64)"`                                // 操作人，未开启认证或自动触发时为空
+	ClientIp  string    `json:"client_ip" gorm:"type:varchar(64)"`                            // 请求来源IP
+	RequestId string    `json:"request_id" gorm:"type:varchar(64);index"`                     // 请求ID，与响应头 X-Request-Id 相同
+	Trigger   string    `json:"trigger" gorm:"column:trigger_type;type:varchar(20);not null"` // api, batch, scheduler, watchdog, system
+	BatchId   string    `json:"batch_id" gorm:"type:varchar(64);index"`                       // 同一次批量操作的日志相同
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 func (s ServiceLog) TableName() string {
 	return "service_log"
 }
+
+// 操作触发方式
+const (
+	TriggerApi       = "api"       // 单个服务的接口调用
+	TriggerBatch     = "batch"     // 批量操作
+	TriggerScheduler = "scheduler" // 定时任务
+	TriggerWatchdog  = "watchdog"  // 自动重启守护
+	TriggerSystem    = "system"    // go_service 自身，如退出时停止托管进程
+)
+
+// OperationSource 操作来源，记录在操作日志中
+type OperationSource struct {
+	Actor     string
+	ClientIp  string
+	RequestId string
+	Trigger   string
+	BatchId   string
+}
+
+// 日志清理任务状态
+const (
+	RetentionStatusRunning = "running"
+	RetentionStatusSuccess = "success"
+	RetentionStatusFailed  = "failed"
+)
+
+// LogRetentionRunModel 一次操作日志清理的记录
+type LogRetentionRunModel struct {
+	Id            int64           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Trigger       string          `json:"trigger" gorm:"column:trigger_type;type:varchar(20);not null"` // schedule, manual
+	Status        string          `json:"status" gorm:"type:varchar(20);not null"`                      // running, success, failed
+	RetentionDays int             `json:"retention_days"`
+	KeepLast      int             `json:"keep_last"`
+	DeletedCount  int64           `json:"deleted_count"`
+	ArchivedCount int64           `json:"archived_count"`
+	ArchiveFile   string          `json:"archive_file" gorm:"type:varchar(500)"`
+	Detail        map[int64]int64 `json:"detail" gorm:"type:text;serializer:json"` // 按服务统计的删除条数
+	Error         string          `json:"error" gorm:"type:text"`
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    *time.Time      `json:"finished_at"`
+}
+
+func (r LogRetentionRunModel) TableName() string {
+	return "log_retention_run"
+}
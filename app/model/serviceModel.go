@@ -2,11 +2,18 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// 运行模式
+const (
+	RunModeCommand = "command" // 执行启动命令后通过端口判断状态
+	RunModeManaged = "managed" // 由 go_service 直接托管进程
+)
+
 type ServiceModel struct {
 	Id              int64     `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name            string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex" binding:"required"`
@@ -16,26 +23,70 @@ type ServiceModel struct {
 	CmdStop         string    `json:"cmd_stop" gorm:"type:text"`
 	CmdRestart      string    `json:"cmd_restart" gorm:"type:text"`
 	Port            int64     `json:"port" gorm:"not null;uniqueIndex" binding:"required,min=1,max=65535"`
-	HealthCheckUrl  string    `json:"health_check_url" gorm:"type:varchar(500)"` // 健康检查URL
-	AutoRestart     bool      `json:"auto_restart" gorm:"default:false"`         // 是否自动重启
-	MaxRestartCount int       `json:"max_restart_count" gorm:"default:3"`        // 最大重启次数
-	RestartInterval int       `json:"restart_interval" gorm:"default:30"`        // 重启间隔(秒)
+	HealthCheckUrl  string    `json:"health_check_url" gorm:"type:varchar(500)"`        // 健康检查URL
+	AutoRestart     bool      `json:"auto_restart" gorm:"default:false"`                // 是否自动重启
+	MaxRestartCount int       `json:"max_restart_count" gorm:"default:3"`               // 最大重启次数
+	RestartInterval int       `json:"restart_interval" gorm:"default:30"`               // 重启间隔(秒)
+	RetentionDays   int       `json:"retention_days" gorm:"default:0"`                  // 操作日志保留天数，0 使用 monitor.retention_days，-1 表示不清理
+	RunMode         string    `json:"run_mode" gorm:"type:varchar(20);default:command"` // 运行模式: command, managed
+	Group           string    `json:"group" gorm:"column:group_name;type:varchar(100)"` // 分组，用于按分组授权
+	Tags            string    `json:"tags" gorm:"type:varchar(500)"`                    // 标签，多个用逗号分隔，用于匹配通知规则
 	Remark          string    `json:"remark" gorm:"type:text"`
 	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	StartupProbe   *ProbeConfig `json:"startup_probe" gorm:"type:text;serializer:json"`   // 启动探针，成功前不执行就绪探针
+	ReadinessProbe *ProbeConfig `json:"readiness_probe" gorm:"type:text;serializer:json"` // 就绪探针，启动/强制重启时等待其成功
+	LivenessProbe  *ProbeConfig `json:"liveness_probe" gorm:"type:text;serializer:json"`  // 存活探针，由健康检查周期执行
 }
 
 type ServiceStatusModel struct {
 	ServiceModel
-	Status  int    `json:"status"`  // 0: 停止, 1: 运行中
-	Pid     string `json:"pid"`     // 进程ID
-	Process string `json:"process"` // 进程名称
+	Status  int                 `json:"status"`           // 0: 停止, 1: 运行中, 2: 运行中但健康检查失败
+	Pid     string              `json:"pid"`              // 进程ID
+	Process string              `json:"process"`          // 进程名称
+	Health  *ServiceHealthModel `json:"health,omitempty"` // 最近一次健康检查结果
+
+	ManagedProcess *ManagedProcessModel `json:"managed_process,omitempty"` // 托管进程信息
+}
+
+// ManagedProcessModel 托管进程信息
+type ManagedProcessModel struct {
+	Pid        int        `json:"pid"`
+	Pgid       int        `json:"pgid"`
+	StartedAt  time.Time  `json:"started_at"`
+	Exited     bool       `json:"exited"`
+	ExitCode   *int       `json:"exit_code,omitempty"`   // 退出码，被信号终止时为 -1
+	ExitSignal string     `json:"exit_signal,omitempty"` // 终止进程的信号
+	ExitedAt   *time.Time `json:"exited_at,omitempty"`
+}
+
+// ServiceHealthModel 健康检查结果
+type ServiceHealthModel struct {
+	Healthy             bool      `json:"healthy"`
+	StatusCode          int       `json:"status_code"`          // HTTP状态码
+	Latency             int64     `json:"latency"`              // 响应耗时(毫秒)
+	Error               string    `json:"error"`                // 错误信息
+	ConsecutiveFailures int       `json:"consecutive_failures"` // 连续失败次数
+	FailureThreshold    int       `json:"failure_threshold"`    // 存活探针的失败阈值，0 表示使用全局配置
+	CheckedAt           time.Time `json:"checked_at"`
 }
 
 func (s ServiceModel) TableName() string {
 	return "service"
 }
 
+// TagList 标签列表
+func (s *ServiceModel) TagList() []string {
+	var tags []string
+	for _, tag := range strings.Split(s.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // Validate 验证服务模型数据
 func (s *ServiceModel) Validate() error {
 	if s.Name == "" {
@@ -50,6 +101,21 @@ func (s *ServiceModel) Validate() error {
 	if s.Port <= 0 || s.Port > 65535 {
 		return fmt.Errorf("端口号必须在1-65535之间")
 	}
+	if s.RunMode != "" && s.RunMode != RunModeCommand && s.RunMode != RunModeManaged {
+		return fmt.Errorf("不支持的运行模式: %s", s.RunMode)
+	}
+	probes := []struct {
+		name  string
+		probe *ProbeConfig
+	}{{"启动探针", s.StartupProbe}, {"就绪探针", s.ReadinessProbe}, {"存活探针", s.LivenessProbe}}
+	for _, p := range probes {
+		if p.probe == nil {
+			continue
+		}
+		if err := p.probe.Validate(); err != nil {
+			return fmt.Errorf("%s配置错误: %v", p.name, err)
+		}
+	}
 	return nil
 }
 
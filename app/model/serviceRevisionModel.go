@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// 服务配置变更类型
+const (
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionDelete   = "delete"
+	RevisionActionRestore  = "restore"
+	RevisionActionBaseline = "baseline" // 记录版本前添加的服务第一次修改时保存的原配置
+)
+
+// ServiceRevisionModel 服务配置的一个版本，每次添加、修改、删除、恢复时保存完整快照
+type ServiceRevisionModel struct {
+	Id            int64         `json:"id" gorm:"primaryKey;autoIncrement"`
+	ServiceId     int64         `json:"service_id" gorm:"not null;uniqueIndex:idx_service_revision"`
+	Revision      int           `json:"revision" gorm:"not null;uniqueIndex:idx_service_revision"` // 服务内从1开始递增的版本号
+	Action        string        `json:"action" gorm:"type:varchar(20);not null"`                   // create, update, delete, restore, baseline
+	Snapshot      *ServiceModel `json:"snapshot" gorm:"type:text;serializer:json"`                 // 变更后的配置，删除时为删除前的配置
+	ChangedFields []string      `json:"changed_fields" gorm:"type:text;serializer:json"`           // 相对上一个版本变化的字段
+	Author        string        `json:"author" gorm:"type:varchar(64)"`
+	RequestId     string        `json:"request_id" gorm:"type:varchar(64)"`
+	Comment       string        `json:"comment" gorm:"type:varchar(500)"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (r ServiceRevisionModel) TableName() string {
+	return "service_revision"
+}
+
+// ServiceFieldChange 服务配置中一个字段的变化，值为 JSON
+type ServiceFieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// ServiceRevisionDiff 两个版本之间的差异
+type ServiceRevisionDiff struct {
+	ServiceId int64                `json:"service_id"`
+	From      int                  `json:"from"`
+	To        int                  `json:"to"`
+	Changes   []ServiceFieldChange `json:"changes"`
+}
+
+// DiffServices 比较两份服务配置，返回按字段名排序的变化，忽略 id 和时间字段
+func DiffServices(before, after *ServiceModel) []ServiceFieldChange {
+	oldFields, newFields := serviceFields(before), serviceFields(after)
+	changes := []ServiceFieldChange{}
+	for field, value := range newFields {
+		if string(oldFields[field]) != string(value) {
+			changes = append(changes, ServiceFieldChange{Field: field, Old: oldFields[field], New: value})
+		}
+	}
+	for field, value := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			changes = append(changes, ServiceFieldChange{Field: field, Old: value, New: json.RawMessage("null")})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// serviceFields 服务配置按 JSON 字段名展开，为 nil 时返回空
+func serviceFields(service *ServiceModel) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if service == nil {
+		return fields
+	}
+	data, err := json.Marshal(service)
+	if err != nil || json.Unmarshal(data, &fields) != nil {
+		return map[string]json.RawMessage{}
+	}
+	delete(fields, "id")
+	delete(fields, "created_at")
+	delete(fields, "updated_at")
+	return fields
+}
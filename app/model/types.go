@@ -1,11 +1,16 @@
 package model
 
+import "time"
+
 // ServiceListRequest 服务列表请求参数
 type ServiceListRequest struct {
 	Page     int    `json:"page" form:"page"`
 	PageSize int    `json:"page_size" form:"page_size"`
 	Name     string `json:"name" form:"name"`
 	Status   *int   `json:"status" form:"status"` // 使用指针以区分0值和未设置
+	Group    string `json:"group" form:"group"`
+
+	Scope *ServiceScope `json:"-" form:"-"` // 当前用户可访问的范围，由权限检查设置
 }
 
 // ServiceListResponse 服务列表响应
@@ -30,19 +35,54 @@ type BatchOperationResponse struct {
 	Results      []map[string]interface{} `json:"results"`
 }
 
-// LogListRequest 日志列表请求参数
+// LogListRequest 操作日志查询参数，按 id 倒序，cursor 为上一页返回的 next_cursor
 type LogListRequest struct {
-	Page      int    `json:"page" form:"page"`
-	PageSize  int    `json:"page_size" form:"page_size"`
+	Cursor    int64  `json:"cursor" form:"cursor"`
+	Limit     int    `json:"limit" form:"limit"` // 默认50，最多500
 	ServiceId *int64 `json:"service_id" form:"service_id"`
 	Operation string `json:"operation" form:"operation"`
 	Status    string `json:"status" form:"status"`
+	Since     string `json:"since" form:"since"` // RFC3339 或 2006-01-02 15:04:05
+	Until     string `json:"until" form:"until"`
+	Keyword   string `json:"q" form:"q"` // 在输出和错误信息中搜索
+	Actor     string `json:"actor" form:"actor"`
+	Trigger   string `json:"trigger" form:"trigger"`
+	RequestId string `json:"request_id" form:"request_id"`
+	BatchId   string `json:"batch_id" form:"batch_id"`
+
+	SinceTime time.Time     `json:"-" form:"-"`
+	UntilTime time.Time     `json:"-" form:"-"`
+	Scope     *ServiceScope `json:"-" form:"-"` // 当前用户可访问的范围，由权限检查设置
 }
 
-// LogListResponse 日志列表响应
+// LogListResponse 操作日志查询结果，has_more 为 false 时没有更多数据
 type LogListResponse struct {
-	List  []ServiceLog `json:"list"`
-	Total int64        `json:"total"`
-	Page  int          `json:"page"`
-	Size  int          `json:"size"`
+	List       []ServiceLog `json:"list"`
+	NextCursor int64        `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
+
+// OutputTailRequest 读取服务最后若干行输出
+type OutputTailRequest struct {
+	Lines int `json:"lines" form:"lines"` // 默认100，最多5000
+}
+
+// OutputRangeRequest 按字节读取服务输出
+type OutputRangeRequest struct {
+	Offset int64 `json:"offset" form:"offset"`
+	Length int64 `json:"length" form:"length"` // 默认且最多1MB
+}
+
+// OutputWindowRequest 按时间范围读取服务输出
+type OutputWindowRequest struct {
+	Since string `json:"since" form:"since"` // RFC3339 或 2006-01-02 15:04:05
+	Until string `json:"until" form:"until"` // 为空表示当前时间
+	Limit int    `json:"limit" form:"limit"` // 默认且最多10000
+}
+
+// OutputStreamRequest 实时输出订阅参数
+type OutputStreamRequest struct {
+	Backlog int    `json:"backlog" form:"backlog"` // 连接时先推送最近的行数，最多5000
+	Filter  string `json:"filter" form:"filter"`   // 过滤正则，只推送匹配的行
+	Stream  string `json:"stream" form:"stream"`   // 来源过滤，多个用逗号分隔，如 stdout,stderr
 }
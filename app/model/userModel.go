@@ -0,0 +1,60 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// UserModel 登录用户
+type UserModel struct {
+	Id           int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Username     string     `json:"username" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Password     string     `json:"-" gorm:"type:varchar(255);not null"` // bcrypt 哈希
+	Role         string     `json:"role" gorm:"type:varchar(20)"`        // 全局角色: viewer, operator, admin，空表示只拥有按服务或分组授予的权限
+	TokenVersion int        `json:"-" gorm:"default:0"`                  // 退出登录时递增，使已签发的令牌失效
+	Enabled      bool       `json:"enabled" gorm:"default:true"`
+	LastLoginAt  *time.Time `json:"last_login_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (u UserModel) TableName() string {
+	return "user"
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse 登录和刷新返回的令牌
+type TokenResponse struct {
+	AccessToken  string     `json:"access_token"`
+	RefreshToken string     `json:"refresh_token"`
+	TokenType    string     `json:"token_type"` // Bearer
+	ExpiresIn    int64      `json:"expires_in"` // 访问令牌有效期(秒)
+	User         *UserModel `json:"user"`
+}
+
+// StreamTicketResponse 实时输出连接的一次性票据
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int64  `json:"expires_in"` // 有效期(秒)
+}
+
+// ValidatePassword 校验密码强度
+func ValidatePassword(password string) error {
+	if len(password) < 8 {
+		return fmt.Errorf("密码长度不能少于8位")
+	}
+	if len(password) > 72 {
+		return fmt.Errorf("密码长度不能超过72位")
+	}
+	return nil
+}
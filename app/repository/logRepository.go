@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"go_service/app/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper 转义 LIKE 中的通配符，转义字符为 !
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// gormLogRepository 使用数据库保存操作日志
+type gormLogRepository struct {
+	db *gorm.DB
+}
+
+func NewGormLogRepository(db *gorm.DB) LogRepository {
+	return &gormLogRepository{db: db}
+}
+
+func (r *gormLogRepository) Create(ctx context.Context, entry *model.ServiceLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *gormLogRepository) CreateBatch(ctx context.Context, entries []model.ServiceLog, batchSize int) error {
+	return r.db.WithContext(ctx).CreateInBatches(entries, batchSize).Error
+}
+
+func (r *gormLogRepository) ListByService(ctx context.Context, serviceId int64, limit int) ([]model.ServiceLog, error) {
+	logs := []model.ServiceLog{}
+	query := r.db.WithContext(ctx).Where("service_id = ?", serviceId).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (r *gormLogRepository) Query(ctx context.Context, filter *model.LogListRequest, cursor int64, limit int) ([]model.ServiceLog, error) {
+	logs := []model.ServiceLog{}
+	if err := r.logQuery(ctx, filter, cursor).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// logQuery 根据查询条件构造查询，cursor 大于 0 时只查询 id 小于 cursor 的日志
+func (r *gormLogRepository) logQuery(ctx context.Context, filter *model.LogListRequest, cursor int64) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.ServiceLog{}).Order("id DESC")
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	if filter.ServiceId != nil {
+		query = query.Where("service_id = ?", *filter.ServiceId)
+	}
+	if filter.Operation != "" {
+		query = query.Where("operation = ?", filter.Operation)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Trigger != "" {
+		query = query.Where("trigger_type = ?", filter.Trigger)
+	}
+	if filter.RequestId != "" {
+		query = query.Where("request_id = ?", filter.RequestId)
+	}
+	if filter.BatchId != "" {
+		query = query.Where("batch_id = ?", filter.BatchId)
+	}
+	if !filter.SinceTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.SinceTime)
+	}
+	if !filter.UntilTime.IsZero() {
+		query = query.Where("created_at < ?", filter.UntilTime)
+	}
+	if filter.Keyword != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Keyword) + "%"
+		query = query.Where("(output LIKE ? ESCAPE '!' OR error LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+
+	// 只返回有权限的服务的日志，分组权限按服务当前所在分组计算
+	if scope := filter.Scope; scope != nil {
+		if len(scope.ServiceIds) == 0 && len(scope.Groups) == 0 {
+			return query.Where("1 = 0")
+		}
+		condition := r.db.Where("1 = 0")
+		if len(scope.ServiceIds) > 0 {
+			condition = condition.Or("service_id IN ?", scope.ServiceIds)
+		}
+		if len(scope.Groups) > 0 {
+			condition = condition.Or("service_id IN (?)", r.db.Model(&model.ServiceModel{}).Select("id").Where("group_name IN ?", scope.Groups))
+		}
+		query = query.Where(condition)
+	}
+	return query
+}
+
+func (r *gormLogRepository) NthLatestId(ctx context.Context, serviceId int64, n int) (int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&model.ServiceLog{}).Where("service_id = ?", serviceId).
+		Order("id DESC").Offset(n-1).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *gormLogRepository) ListExpired(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64, limit int) ([]model.ServiceLog, error) {
+	logs := []model.ServiceLog{}
+	if err := r.expiredQuery(ctx, serviceId, cutoff, beforeId).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (r *gormLogRepository) ListExpiredIds(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64, limit int) ([]int64, error) {
+	ids := []int64{}
+	if err := r.expiredQuery(ctx, serviceId, cutoff, beforeId).Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// expiredQuery 服务在 cutoff 之前的日志，beforeId 大于 0 时只查询ID小于 beforeId 的日志
+func (r *gormLogRepository) expiredQuery(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.ServiceLog{}).Where("service_id = ? AND created_at < ?", serviceId, cutoff)
+	if beforeId > 0 {
+		query = query.Where("id < ?", beforeId)
+	}
+	return query.Order("id")
+}
+
+func (r *gormLogRepository) DeleteByIds(ctx context.Context, ids []int64) (int64, error) {
+	result := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.ServiceLog{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormLogRepository) Stats(ctx context.Context, since, until time.Time) (*LogStats, error) {
+	stats := &LogStats{Operations: []OperationCount{}}
+	logs := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.ServiceLog{})
+	}
+	if err := logs().Count(&stats.Total).Error; err != nil {
+		return nil, err
+	}
+	// 按时间范围查询，MySQL 和 SQLite 通用且可以使用索引
+	if err := logs().Where("created_at >= ? AND created_at < ?", since, until).Count(&stats.RangeCount).Error; err != nil {
+		return nil, err
+	}
+	if err := logs().Where("status = ?", "success").Count(&stats.Success).Error; err != nil {
+		return nil, err
+	}
+	if err := logs().Where("status = ?", "failed").Count(&stats.Failed).Error; err != nil {
+		return nil, err
+	}
+	if err := logs().Select("operation, COUNT(*) as count").Group("operation").Scan(&stats.Operations).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,482 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/model"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryStore 内存中的服务、操作日志和配置版本，服务和日志的存储共用，服务变更与日志在同一把锁内写入
+type memoryStore struct {
+	mutex          sync.RWMutex
+	services       map[int64]model.ServiceModel
+	logs           []model.ServiceLog // 按ID升序
+	revisions      []model.ServiceRevisionModel
+	nextServiceId  int64
+	nextLogId      int64
+	nextRevisionId int64
+}
+
+var (
+	_ ServiceRepository = (*MemoryServiceRepository)(nil)
+	_ LogRepository     = (*MemoryLogRepository)(nil)
+)
+
+// MemoryServiceRepository 内存中的服务存储，用于测试，并发安全
+type MemoryServiceRepository struct {
+	store *memoryStore
+}
+
+// MemoryLogRepository 内存中的操作日志存储，用于测试，并发安全
+type MemoryLogRepository struct {
+	store *memoryStore
+}
+
+// NewMemoryRepositories 创建共用一份数据的内存服务存储和日志存储
+// 与数据库相同，服务名称和端口唯一，服务变更的操作日志写入日志存储
+func NewMemoryRepositories() (*MemoryServiceRepository, *MemoryLogRepository) {
+	store := &memoryStore{services: map[int64]model.ServiceModel{}}
+	return &MemoryServiceRepository{store: store}, &MemoryLogRepository{store: store}
+}
+
+func (r *MemoryServiceRepository) Get(ctx context.Context, id int64) (*model.ServiceModel, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	service, ok := r.store.services[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneService(service), nil
+}
+
+func (r *MemoryServiceRepository) FindByName(ctx context.Context, name string, excludeId int64) (*model.ServiceModel, error) {
+	return r.findOne(func(service model.ServiceModel) bool { return service.Name == name }, excludeId)
+}
+
+func (r *MemoryServiceRepository) FindByPort(ctx context.Context, port int64, excludeId int64) (*model.ServiceModel, error) {
+	return r.findOne(func(service model.ServiceModel) bool { return service.Port == port }, excludeId)
+}
+
+// findOne 获取ID最小的满足条件的服务，excludeId 大于 0 时排除该服务
+func (r *MemoryServiceRepository) findOne(match func(model.ServiceModel) bool, excludeId int64) (*model.ServiceModel, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	for _, service := range r.store.sortedServices() {
+		if service.Id != excludeId && match(service) {
+			return cloneService(service), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryServiceRepository) List(ctx context.Context, filter *model.ServiceListRequest) ([]model.ServiceModel, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	return r.store.filterServices(func(service model.ServiceModel) bool { return matchServiceFilter(service, filter) }), nil
+}
+
+func (r *MemoryServiceRepository) ListPage(ctx context.Context, filter *model.ServiceListRequest, offset, limit int) ([]model.ServiceModel, int64, error) {
+	services, _ := r.List(ctx, filter)
+	total := int64(len(services))
+	if offset >= len(services) {
+		return []model.ServiceModel{}, total, nil
+	}
+	services = services[offset:]
+	if limit > 0 && limit < len(services) {
+		services = services[:limit]
+	}
+	return services, total, nil
+}
+
+func (r *MemoryServiceRepository) ListAutoRestart(ctx context.Context) ([]model.ServiceModel, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	return r.store.filterServices(func(service model.ServiceModel) bool { return service.AutoRestart }), nil
+}
+
+func (r *MemoryServiceRepository) ListHealthCheck(ctx context.Context) ([]model.ServiceModel, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	return r.store.filterServices(func(service model.ServiceModel) bool {
+		return service.HealthCheckUrl != "" || service.LivenessProbe != nil
+	}), nil
+}
+
+func (r *MemoryServiceRepository) Count(ctx context.Context) (int64, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	return int64(len(r.store.services)), nil
+}
+
+func (r *MemoryServiceRepository) Create(ctx context.Context, service *model.ServiceModel, change ServiceChange) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if service.Id > 0 {
+		if _, ok := r.store.services[service.Id]; ok {
+			return fmt.Errorf("%w: 服务ID %d 已存在", ErrDuplicate, service.Id)
+		}
+	}
+	if err := r.store.checkUnique(service); err != nil {
+		return err
+	}
+
+	if service.Id <= 0 {
+		r.store.nextServiceId++
+		service.Id = r.store.nextServiceId
+	} else if service.Id > r.store.nextServiceId {
+		r.store.nextServiceId = service.Id
+	}
+	now := time.Now()
+	service.CreatedAt, service.UpdatedAt = now, now
+	r.store.services[service.Id] = *cloneService(*service)
+	r.store.saveServiceChange(service, change)
+	return nil
+}
+
+func (r *MemoryServiceRepository) Update(ctx context.Context, service *model.ServiceModel, change ServiceChange) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	existing, ok := r.store.services[service.Id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := r.store.checkUnique(service); err != nil {
+		return err
+	}
+
+	service.CreatedAt = existing.CreatedAt
+	service.UpdatedAt = time.Now()
+	r.store.services[service.Id] = *cloneService(*service)
+	r.store.saveServiceChange(service, change)
+	return nil
+}
+
+func (r *MemoryServiceRepository) Delete(ctx context.Context, service *model.ServiceModel, change ServiceChange) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	delete(r.store.services, service.Id)
+	r.store.saveServiceChange(service, change)
+	return nil
+}
+
+// Revisions 服务的所有版本，按版本号升序
+func (r *MemoryServiceRepository) Revisions(serviceId int64) []model.ServiceRevisionModel {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	revisions := []model.ServiceRevisionModel{}
+	for _, revision := range r.store.revisions {
+		if revision.ServiceId == serviceId {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+// checkUnique 与数据库的唯一索引相同，名称和端口不能与其他服务重复
+func (s *memoryStore) checkUnique(service *model.ServiceModel) error {
+	for _, other := range s.services {
+		if other.Id == service.Id {
+			continue
+		}
+		if other.Name == service.Name {
+			return fmt.Errorf("%w: 服务名称 %s 已存在", ErrDuplicate, service.Name)
+		}
+		if other.Port == service.Port {
+			return fmt.Errorf("%w: 端口 %d 已存在", ErrDuplicate, service.Port)
+		}
+	}
+	return nil
+}
+
+// saveServiceChange 保存服务变更的操作日志和版本，调用方持有写锁
+func (s *memoryStore) saveServiceChange(service *model.ServiceModel, change ServiceChange) {
+	change.Log.ServiceId = service.Id
+	s.appendLog(&change.Log)
+
+	latest := 0
+	for _, revision := range s.revisions {
+		if revision.ServiceId == service.Id && revision.Revision > latest {
+			latest = revision.Revision
+		}
+	}
+	if latest == 0 && change.Baseline != nil {
+		baseline := *change.Baseline
+		baseline.ServiceId = service.Id
+		baseline.Revision = 1
+		s.appendRevision(baseline)
+		latest = 1
+	}
+
+	change.Revision.ServiceId = service.Id
+	change.Revision.Revision = latest + 1
+	change.Revision.Snapshot = cloneService(*service)
+	s.appendRevision(change.Revision)
+}
+
+func (s *memoryStore) appendRevision(revision model.ServiceRevisionModel) {
+	s.nextRevisionId++
+	revision.Id = s.nextRevisionId
+	revision.CreatedAt = time.Now()
+	s.revisions = append(s.revisions, revision)
+}
+
+// appendLog 写入一条日志并设置ID和创建时间，调用方持有写锁
+func (s *memoryStore) appendLog(entry *model.ServiceLog) {
+	s.nextLogId++
+	entry.Id = s.nextLogId
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	s.logs = append(s.logs, *entry)
+}
+
+// sortedServices 所有服务，按ID升序
+func (s *memoryStore) sortedServices() []model.ServiceModel {
+	services := make([]model.ServiceModel, 0, len(s.services))
+	for _, service := range s.services {
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Id < services[j].Id })
+	return services
+}
+
+// filterServices 满足条件的服务的副本，按ID升序
+func (s *memoryStore) filterServices(match func(model.ServiceModel) bool) []model.ServiceModel {
+	services := []model.ServiceModel{}
+	for _, service := range s.sortedServices() {
+		if match(service) {
+			services = append(services, *cloneService(service))
+		}
+	}
+	return services
+}
+
+// matchServiceFilter 按名称(不区分大小写的包含)、分组和权限范围过滤
+func matchServiceFilter(service model.ServiceModel, filter *model.ServiceListRequest) bool {
+	if filter.Name != "" && !strings.Contains(strings.ToLower(service.Name), strings.ToLower(filter.Name)) {
+		return false
+	}
+	if filter.Group != "" && service.Group != filter.Group {
+		return false
+	}
+	if scope := filter.Scope; scope != nil && !containsId(scope.ServiceIds, service.Id) && !containsString(scope.Groups, service.Group) {
+		return false
+	}
+	return true
+}
+
+func containsId(ids []int64, id int64) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneService 复制服务配置，探针配置不与原配置共用
+func cloneService(service model.ServiceModel) *model.ServiceModel {
+	service.StartupProbe = cloneProbe(service.StartupProbe)
+	service.ReadinessProbe = cloneProbe(service.ReadinessProbe)
+	service.LivenessProbe = cloneProbe(service.LivenessProbe)
+	return &service
+}
+
+func cloneProbe(probe *model.ProbeConfig) *model.ProbeConfig {
+	if probe == nil {
+		return nil
+	}
+	cloned := *probe
+	if probe.ExpectHeaders != nil {
+		cloned.ExpectHeaders = make(map[string]string, len(probe.ExpectHeaders))
+		for key, value := range probe.ExpectHeaders {
+			cloned.ExpectHeaders[key] = value
+		}
+	}
+	return &cloned
+}
+
+func (r *MemoryLogRepository) Create(ctx context.Context, entry *model.ServiceLog) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	r.store.appendLog(entry)
+	return nil
+}
+
+func (r *MemoryLogRepository) CreateBatch(ctx context.Context, entries []model.ServiceLog, batchSize int) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	for i := range entries {
+		r.store.appendLog(&entries[i])
+	}
+	return nil
+}
+
+func (r *MemoryLogRepository) ListByService(ctx context.Context, serviceId int64, limit int) ([]model.ServiceLog, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	logs := []model.ServiceLog{}
+	for i := len(r.store.logs) - 1; i >= 0; i-- {
+		if r.store.logs[i].ServiceId == serviceId {
+			logs = append(logs, r.store.logs[i])
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].CreatedAt.After(logs[j].CreatedAt) })
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs, nil
+}
+
+func (r *MemoryLogRepository) Query(ctx context.Context, filter *model.LogListRequest, cursor int64, limit int) ([]model.ServiceLog, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	logs := []model.ServiceLog{}
+	for i := len(r.store.logs) - 1; i >= 0 && len(logs) < limit; i-- {
+		entry := r.store.logs[i]
+		if (cursor <= 0 || entry.Id < cursor) && r.store.matchLogFilter(entry, filter) {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+// matchLogFilter 日志是否满足查询条件，关键字不区分大小写，分组权限按服务当前所在分组计算
+func (s *memoryStore) matchLogFilter(entry model.ServiceLog, filter *model.LogListRequest) bool {
+	switch {
+	case filter.ServiceId != nil && entry.ServiceId != *filter.ServiceId,
+		filter.Operation != "" && entry.Operation != filter.Operation,
+		filter.Status != "" && entry.Status != filter.Status,
+		filter.Actor != "" && entry.Actor != filter.Actor,
+		filter.Trigger != "" && entry.Trigger != filter.Trigger,
+		filter.RequestId != "" && entry.RequestId != filter.RequestId,
+		filter.BatchId != "" && entry.BatchId != filter.BatchId,
+		!filter.SinceTime.IsZero() && entry.CreatedAt.Before(filter.SinceTime),
+		!filter.UntilTime.IsZero() && !entry.CreatedAt.Before(filter.UntilTime):
+		return false
+	}
+	if filter.Keyword != "" {
+		keyword := strings.ToLower(filter.Keyword)
+		if !strings.Contains(strings.ToLower(entry.Output), keyword) && !strings.Contains(strings.ToLower(entry.Error), keyword) {
+			return false
+		}
+	}
+	if scope := filter.Scope; scope != nil && !containsId(scope.ServiceIds, entry.ServiceId) {
+		// 服务已删除时只能按服务ID授权
+		service, ok := s.services[entry.ServiceId]
+		if !ok || !containsString(scope.Groups, service.Group) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *MemoryLogRepository) NthLatestId(ctx context.Context, serviceId int64, n int) (int64, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	for i := len(r.store.logs) - 1; i >= 0; i-- {
+		if r.store.logs[i].ServiceId == serviceId {
+			if n--; n == 0 {
+				return r.store.logs[i].Id, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+func (r *MemoryLogRepository) ListExpired(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64, limit int) ([]model.ServiceLog, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	logs := []model.ServiceLog{}
+	for _, entry := range r.store.logs {
+		if len(logs) >= limit {
+			break
+		}
+		if entry.ServiceId == serviceId && entry.CreatedAt.Before(cutoff) && (beforeId <= 0 || entry.Id < beforeId) {
+			logs = append(logs, entry)
+		}
+	}
+	return logs, nil
+}
+
+func (r *MemoryLogRepository) ListExpiredIds(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64, limit int) ([]int64, error) {
+	logs, _ := r.ListExpired(ctx, serviceId, cutoff, beforeId, limit)
+	ids := make([]int64, 0, len(logs))
+	for _, entry := range logs {
+		ids = append(ids, entry.Id)
+	}
+	return ids, nil
+}
+
+func (r *MemoryLogRepository) DeleteByIds(ctx context.Context, ids []int64) (int64, error) {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	remove := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	kept := r.store.logs[:0]
+	for _, entry := range r.store.logs {
+		if !remove[entry.Id] {
+			kept = append(kept, entry)
+		}
+	}
+	deleted := int64(len(r.store.logs) - len(kept))
+	r.store.logs = kept
+	return deleted, nil
+}
+
+func (r *MemoryLogRepository) Stats(ctx context.Context, since, until time.Time) (*LogStats, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	stats := &LogStats{Total: int64(len(r.store.logs)), Operations: []OperationCount{}}
+	counts := map[string]int64{}
+	for _, entry := range r.store.logs {
+		if !entry.CreatedAt.Before(since) && entry.CreatedAt.Before(until) {
+			stats.RangeCount++
+		}
+		switch entry.Status {
+		case "success":
+			stats.Success++
+		case "failed":
+			stats.Failed++
+		}
+		counts[entry.Operation]++
+	}
+	for operation, count := range counts {
+		stats.Operations = append(stats.Operations, OperationCount{Operation: operation, Count: count})
+	}
+	sort.Slice(stats.Operations, func(i, j int) bool { return stats.Operations[i].Operation < stats.Operations[j].Operation })
+	return stats, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go_service/app/model"
+	"time"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate 服务的ID、名称或端口与已有的服务重复
+var ErrDuplicate = errors.New("duplicated key")
+
+// ServiceChange 与服务配置变更在同一事务中保存的操作日志和配置版本
+type ServiceChange struct {
+	Log      model.ServiceLog            // 操作日志，ServiceId 由存储设置
+	Revision model.ServiceRevisionModel  // 新版本，ServiceId、Revision 和 Snapshot 由存储设置，Snapshot 为写入后的服务
+	Baseline *model.ServiceRevisionModel // 服务还没有任何版本时先保存的基准版本，ServiceId 和 Revision 由存储设置
+}
+
+// ServiceRepository 服务配置的存储
+type ServiceRepository interface {
+	// Get 按ID获取服务，不存在时返回 ErrNotFound
+	Get(ctx context.Context, id int64) (*model.ServiceModel, error)
+	// FindByName 获取名称为 name 的服务，excludeId 大于 0 时排除该服务，不存在时返回 ErrNotFound
+	FindByName(ctx context.Context, name string, excludeId int64) (*model.ServiceModel, error)
+	// FindByPort 获取端口为 port 的服务，excludeId 大于 0 时排除该服务，不存在时返回 ErrNotFound
+	FindByPort(ctx context.Context, port int64, excludeId int64) (*model.ServiceModel, error)
+	// List 按名称、分组和权限范围查询服务，按ID升序，不分页
+	List(ctx context.Context, filter *model.ServiceListRequest) ([]model.ServiceModel, error)
+	// ListPage 按条件分页查询服务，返回当前页和总数
+	ListPage(ctx context.Context, filter *model.ServiceListRequest, offset, limit int) ([]model.ServiceModel, int64, error)
+	// ListAutoRestart 开启自动重启的服务
+	ListAutoRestart(ctx context.Context) ([]model.ServiceModel, error)
+	// ListHealthCheck 配置了健康检查地址或存活探针的服务
+	ListHealthCheck(ctx context.Context) ([]model.ServiceModel, error)
+	// Count 服务总数
+	Count(ctx context.Context) (int64, error)
+	// Create 添加服务，Id 大于 0 时使用该ID，ID、名称或端口重复时返回 ErrDuplicate
+	Create(ctx context.Context, service *model.ServiceModel, change ServiceChange) error
+	// Update 使用 service 的所有字段替换已有的配置(添加时间除外)，写入后 service 为保存的配置，
+	// 名称或端口与其他服务重复时返回 ErrDuplicate
+	Update(ctx context.Context, service *model.ServiceModel, change ServiceChange) error
+	// Delete 删除服务及按服务的授权，操作日志和版本保留
+	Delete(ctx context.Context, service *model.ServiceModel, change ServiceChange) error
+}
+
+// LogRepository 操作日志的存储
+type LogRepository interface {
+	// Create 写入一条日志
+	Create(ctx context.Context, entry *model.ServiceLog) error
+	// CreateBatch 批量写入日志，每次最多写入 batchSize 条
+	CreateBatch(ctx context.Context, entries []model.ServiceLog, batchSize int) error
+	// ListByService 服务最近的日志，按时间倒序，limit 小于等于 0 时不限制条数
+	ListByService(ctx context.Context, serviceId int64, limit int) ([]model.ServiceLog, error)
+	// Query 按条件查询日志，按ID倒序，cursor 大于 0 时只查询ID小于 cursor 的日志
+	Query(ctx context.Context, filter *model.LogListRequest, cursor int64, limit int) ([]model.ServiceLog, error)
+	// NthLatestId 服务倒数第 n 条日志的ID，不足 n 条时为 0
+	NthLatestId(ctx context.Context, serviceId int64, n int) (int64, error)
+	// ListExpired 服务在 cutoff 之前且ID小于 beforeId(为 0 时不限制)的日志，按ID升序
+	ListExpired(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64, limit int) ([]model.ServiceLog, error)
+	// ListExpiredIds 与 ListExpired 条件相同，只返回ID
+	ListExpiredIds(ctx context.Context, serviceId int64, cutoff time.Time, beforeId int64, limit int) ([]int64, error)
+	// DeleteByIds 删除日志，返回删除的条数
+	DeleteByIds(ctx context.Context, ids []int64) (int64, error)
+	// Stats 日志统计，since 到 until 之间的日志数为 RangeCount
+	Stats(ctx context.Context, since, until time.Time) (*LogStats, error)
+}
+
+// LogStats 操作日志统计
+type LogStats struct {
+	Total      int64
+	RangeCount int64
+	Success    int64
+	Failed     int64
+	Operations []OperationCount
+}
+
+// OperationCount 每种操作的日志数
+type OperationCount struct {
+	Operation string `json:"operation"`
+	Count     int64  `json:"count"`
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"go_service/app/model"
+
+	"gorm.io/gorm"
+)
+
+// gormServiceRepository 使用数据库保存服务配置
+type gormServiceRepository struct {
+	db *gorm.DB
+}
+
+func NewGormServiceRepository(db *gorm.DB) ServiceRepository {
+	return &gormServiceRepository{db: db}
+}
+
+func (r *gormServiceRepository) Get(ctx context.Context, id int64) (*model.ServiceModel, error) {
+	var service model.ServiceModel
+	if err := r.db.WithContext(ctx).First(&service, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &service, nil
+}
+
+func (r *gormServiceRepository) FindByName(ctx context.Context, name string, excludeId int64) (*model.ServiceModel, error) {
+	return r.findOne(ctx, "name = ?", name, excludeId)
+}
+
+func (r *gormServiceRepository) FindByPort(ctx context.Context, port int64, excludeId int64) (*model.ServiceModel, error) {
+	return r.findOne(ctx, "port = ?", port, excludeId)
+}
+
+// findOne 按条件获取一个服务，excludeId 大于 0 时排除该服务
+func (r *gormServiceRepository) findOne(ctx context.Context, condition string, value interface{}, excludeId int64) (*model.ServiceModel, error) {
+	var service model.ServiceModel
+	query := r.db.WithContext(ctx).Where(condition, value)
+	if excludeId > 0 {
+		query = query.Where("id != ?", excludeId)
+	}
+	if err := query.First(&service).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &service, nil
+}
+
+func (r *gormServiceRepository) List(ctx context.Context, filter *model.ServiceListRequest) ([]model.ServiceModel, error) {
+	services := []model.ServiceModel{}
+	if err := r.listQuery(ctx, filter).Order("id").Find(&services).Error; err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (r *gormServiceRepository) ListPage(ctx context.Context, filter *model.ServiceListRequest, offset, limit int) ([]model.ServiceModel, int64, error) {
+	var total int64
+	if err := r.listQuery(ctx, filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	services := []model.ServiceModel{}
+	if err := r.listQuery(ctx, filter).Order("id").Offset(offset).Limit(limit).Find(&services).Error; err != nil {
+		return nil, 0, err
+	}
+	return services, total, nil
+}
+
+// listQuery 按名称、分组和权限范围过滤
+func (r *gormServiceRepository) listQuery(ctx context.Context, filter *model.ServiceListRequest) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.ServiceModel{})
+
+	// 按名称过滤
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+
+	// 按分组过滤
+	if filter.Group != "" {
+		query = query.Where("group_name = ?", filter.Group)
+	}
+
+	// 只返回有权限的服务
+	if scope := filter.Scope; scope != nil {
+		if len(scope.ServiceIds) == 0 && len(scope.Groups) == 0 {
+			return query.Where("1 = 0")
+		}
+		condition := r.db.Where("1 = 0")
+		if len(scope.ServiceIds) > 0 {
+			condition = condition.Or("id IN ?", scope.ServiceIds)
+		}
+		if len(scope.Groups) > 0 {
+			condition = condition.Or("group_name IN ?", scope.Groups)
+		}
+		query = query.Where(condition)
+	}
+	return query
+}
+
+func (r *gormServiceRepository) ListAutoRestart(ctx context.Context) ([]model.ServiceModel, error) {
+	services := []model.ServiceModel{}
+	if err := r.db.WithContext(ctx).Where("auto_restart = ?", true).Find(&services).Error; err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (r *gormServiceRepository) ListHealthCheck(ctx context.Context) ([]model.ServiceModel, error) {
+	services := []model.ServiceModel{}
+	if err := r.db.WithContext(ctx).Where("health_check_url <> ? OR liveness_probe IS NOT NULL", "").Find(&services).Error; err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+func (r *gormServiceRepository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&model.ServiceModel{}).Count(&total).Error
+	return total, err
+}
+
+func (r *gormServiceRepository) Create(ctx context.Context, service *model.ServiceModel, change ServiceChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(service).Error; err != nil {
+			return r.translateWriteError(err)
+		}
+		return saveServiceChange(tx, service, change)
+	})
+}
+
+func (r *gormServiceRepository) Update(ctx context.Context, service *model.ServiceModel, change ServiceChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Select("*") 写入零值字段
+		result := tx.Model(service).Select("*").Omit("id", "created_at").Updates(service)
+		if result.Error != nil {
+			return r.translateWriteError(result.Error)
+		}
+		if err := tx.First(service, service.Id).Error; err != nil {
+			return translateError(err)
+		}
+		return saveServiceChange(tx, service, change)
+	})
+}
+
+func (r *gormServiceRepository) Delete(ctx context.Context, service *model.ServiceModel, change ServiceChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.ServiceModel{}, service.Id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("service_id = ?", service.Id).Delete(&model.UserPermissionModel{}).Error; err != nil {
+			return err
+		}
+		return saveServiceChange(tx, service, change)
+	})
+}
+
+// saveServiceChange 在事务中保存服务变更的操作日志和版本
+func saveServiceChange(tx *gorm.DB, service *model.ServiceModel, change ServiceChange) error {
+	change.Log.ServiceId = service.Id
+	if err := tx.Create(&change.Log).Error; err != nil {
+		return err
+	}
+
+	var latest int
+	err := tx.Model(&model.ServiceRevisionModel{}).Where("service_id = ?", service.Id).
+		Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+	if latest == 0 && change.Baseline != nil {
+		baseline := *change.Baseline
+		baseline.ServiceId = service.Id
+		baseline.Revision = 1
+		if err := tx.Create(&baseline).Error; err != nil {
+			return err
+		}
+		latest = 1
+	}
+
+	snapshot := *service
+	change.Revision.ServiceId = service.Id
+	change.Revision.Revision = latest + 1
+	change.Revision.Snapshot = &snapshot
+	return tx.Create(&change.Revision).Error
+}
+
+// translateWriteError 将唯一索引冲突转换为 ErrDuplicate
+func (r *gormServiceRepository) translateWriteError(err error) error {
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok {
+		if translator.Translate(err) == gorm.ErrDuplicatedKey {
+			return ErrDuplicate
+		}
+	}
+	return err
+}
+
+// translateError 将记录不存在转换为 ErrNotFound
+func translateError(err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return ErrNotFound
+	}
+	return err
+}
@@ -1,20 +1,60 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"go_service/app/config"
 	"go_service/app/controller"
 	"go_service/app/global"
 	"go_service/app/middleware"
+	"go_service/app/model"
+	"go_service/app/service"
 	"log"
+	"regexp"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// sensitiveQuery 访问日志中需要隐去的查询参数
+var sensitiveQuery = regexp.MustCompile(`(?i)([?&](?:token|ticket|access_token)=)[^&]*`)
+
+// redactQuery 隐去请求路径中的令牌和票据，避免写入访问日志
+func redactQuery(path string) string {
+	return sensitiveQuery.ReplaceAllString(path, "${1}***")
+}
+
 func RunHttp() {
 	global.InitConfig()
 	global.InitDatabase()
 
+	// 用户表为空时创建初始管理员
+	if err := service.NewAuthService(global.GetDefaultDb()).EnsureDefaultUser(context.Background()); err != nil {
+		log.Printf("创建初始管理员失败: %v", err)
+	}
+
+	// 告警发送协程最后停止，发送完守护协程产生的告警
+	alertService := service.NewAlertService(global.GetDefaultDb())
+	alertService.Start()
+
+	// 启动健康检查和自动重启守护，退出时先停止自动重启
+	var workers []worker
+	if monitor := config.GlobalConfig.Monitor; monitor.Enabled {
+		healthCheck := service.NewHealthCheckService(global.GetDefaultDb(), monitor.CheckInterval, monitor.Timeout)
+		healthCheck.Start()
+
+		watchdog := service.NewWatchdogService(global.GetDefaultDb(), monitor.WatchdogInterval)
+		watchdog.SetUnhealthyThreshold(monitor.UnhealthyThreshold)
+		watchdog.Start()
+		workers = append(workers, watchdog, healthCheck)
+	}
+
+	// 每天清理过期的操作日志
+	retention := service.NewRetentionService(global.GetDefaultDb())
+	retention.Start()
+	workers = append(workers, retention, alertService)
+
 	// 设置Gin模式
 	if config.GlobalConfig.App.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -25,10 +65,14 @@ func RunHttp() {
 	// 创建性能监控中间件
 
 	// 中间件
+	r.Use(middleware.Metrics())
+	r.Use(middleware.RequestId())
 	r.Use(gin.Recovery())
 	r.Use(middleware.ExceptErr())
 	r.Use(middleware.HttpInterceptor())
 	r.Use(middleware.Cors())
+	r.Use(middleware.IPAllowlist())
+	r.Use(middleware.RateLimit())
 	r.Use(middleware.ServerContextHandler())
 	r.Use(middleware.Logger())
 
@@ -40,7 +84,7 @@ func RunHttp() {
 			param.Latency,
 			param.ClientIP,
 			param.Method,
-			param.Path,
+			redactQuery(param.Path),
 		)
 	}))
 
@@ -50,47 +94,134 @@ func RunHttp() {
 
 	// 首页
 	r.GET("/", controller.NewServiceController().Index)
+	r.GET("/login", controller.NewAuthController().LoginPage)
+
+	// 健康检查，供负载均衡和监控使用，不需要令牌
+	if healthPath := strings.TrimRight(config.GlobalConfig.Monitor.HealthCheckPath, "/"); healthPath != "" {
+		healthController := controller.NewHealthController()
+		r.GET(healthPath, healthController.Health)
+		r.GET(healthPath+"/live", healthController.Live)
+		r.GET(healthPath+"/ready", healthController.Ready)
+	}
 
-	// API路由组
-	api := r.Group("/api/v1")
+	// Prometheus 指标，不需要令牌，可通过 allowed_ips 限制访问来源
+	if metricsPath := config.GlobalConfig.Monitor.MetricsPath; metricsPath != "" {
+		service.RegisterMetrics(global.GetDefaultDb())
+		r.GET(metricsPath, gin.WrapH(promhttp.Handler()))
+	}
+
+	// API路由组，开启认证时校验访问令牌，并按用户限流，操作人和请求ID记录到操作日志中
+	api := r.Group("/api/v1", middleware.Auth(), middleware.PrincipalRateLimit(), middleware.OperationSource())
 	{
-		// 服务管理
+		// 认证
+		auth := api.Group("/auth")
+		{
+			authController := controller.NewAuthController()
+			auth.POST("/login", authController.Login)
+			auth.POST("/refresh", authController.Refresh)
+			auth.POST("/logout", authController.Logout)
+			auth.GET("/me", authController.Me)
+		}
+
+		// 用户和授权管理
+		users := api.Group("/user", middleware.RequireRole(model.RoleAdmin))
+		{
+			userController := controller.NewUserController()
+			users.GET("/all", userController.List)
+			users.POST("/add", userController.Add)
+			users.POST("/update", userController.Update)
+			users.POST("/delete/:id", userController.Delete)
+			users.GET("/permissions", userController.Permissions)
+			users.POST("/grant", userController.Grant)
+			users.POST("/revoke/:id", userController.Revoke)
+		}
+
+		// 通知渠道、规则和静默窗口，需要 admin
+		notification := api.Group("/notification", middleware.RequireRole(model.RoleAdmin))
+		{
+			notificationController := controller.NewNotificationController()
+			notification.GET("/channel/all", notificationController.Channels)
+			notification.POST("/channel/save", notificationController.SaveChannel)
+			notification.POST("/channel/delete/:id", notificationController.DeleteChannel)
+			notification.GET("/rule/all", notificationController.Rules)
+			notification.POST("/rule/save", notificationController.SaveRule)
+			notification.POST("/rule/delete/:id", notificationController.DeleteRule)
+			notification.GET("/silence/all", notificationController.Silences)
+			notification.POST("/silence/add", notificationController.AddSilence)
+			notification.POST("/silence/expire/:id", notificationController.ExpireSilence)
+			notification.GET("/incidents", notificationController.Incidents)
+		}
+
+		// 操作日志，查看需要 viewer，只返回有权限的服务的日志，清理需要 admin
+		logs := api.Group("/logs")
+		{
+			logController := controller.NewLogController()
+			logs.GET("", middleware.WithServiceScope(model.RoleViewer), logController.List)
+			logs.GET("/export", middleware.WithServiceScope(model.RoleViewer), logController.Export)
+			logs.GET("/stats", middleware.RequireRole(model.RoleViewer), logController.Stats)
+			logs.GET("/service/:id", middleware.RequireServiceRole(model.RoleViewer), logController.ServiceLogs)
+			logs.GET("/retention/runs", middleware.RequireRole(model.RoleAdmin), logController.RetentionRuns)
+			logs.POST("/retention/run", middleware.RequireRole(model.RoleAdmin), logController.RunRetention)
+		}
+
+		// 服务管理，查看需要 viewer，增删改需要 admin，可按服务或分组授权
 		services := api.Group("/service")
 		{
 			serviceController := controller.NewServiceController()
-			services.POST("/add", serviceController.Add)
-			services.GET("/findById/:id", serviceController.FindById)
-			services.GET("/findByName/:key", serviceController.FindByName)
-			services.POST("/delete/:id", serviceController.DeleteById)
-			services.GET("/all", serviceController.FindAll)
-			services.POST("/update", serviceController.Update)
+			services.POST("/add", middleware.RequireServiceCreateRole(model.RoleAdmin), serviceController.Add)
+			services.GET("/findById/:id", middleware.RequireServiceRole(model.RoleViewer), serviceController.FindById)
+			services.GET("/findByName/:key", middleware.RequireServiceNameRole(model.RoleViewer), serviceController.FindByName)
+			services.POST("/delete/:id", middleware.RequireServiceRole(model.RoleAdmin), serviceController.DeleteById)
+			services.GET("/all", middleware.WithServiceScope(model.RoleViewer), serviceController.FindAll)
+			services.POST("/update", middleware.RequireServiceUpdateRole(model.RoleAdmin), serviceController.Update)
+			outputController := controller.NewOutputController()
+			services.POST("/:id/logs/stream-ticket", middleware.RequireServiceRole(model.RoleViewer), outputController.StreamTicket)
+			services.GET("/:id/logs/stream", middleware.RequireServiceRole(model.RoleViewer), outputController.Stream)
+
+			// 配置版本，已删除的服务只有全局角色可以查看和恢复
+			revisionController := controller.NewRevisionController()
+			services.GET("/deleted", middleware.RequireRole(model.RoleViewer), revisionController.Deleted)
+			services.GET("/:id/revisions", middleware.RequireServiceRole(model.RoleViewer), revisionController.List)
+			services.GET("/:id/revisions/diff", middleware.RequireServiceRole(model.RoleViewer), revisionController.Diff)
+			services.GET("/:id/revisions/:revision", middleware.RequireServiceRole(model.RoleViewer), revisionController.Get)
+			services.POST("/:id/revisions/:revision/restore", middleware.RequireServiceRole(model.RoleAdmin), revisionController.Restore)
 		}
 
-		// 服务操作
-		cmd := api.Group("/cmd")
+		// 服务操作，启动、停止、重启需要 operator，强制重启和强杀需要 admin，使用独立的限流额度
+		cmd := api.Group("/cmd", middleware.CommandRateLimit())
 		{
 			cmdController := controller.NewCmdController()
-			cmd.POST("/start/:id", cmdController.Start)
-			cmd.POST("/stop/:id", cmdController.Stop)
-			cmd.POST("/restart/:id", cmdController.Restart)
-			cmd.POST("/force-restart/:id", cmdController.ForcedRestart)
-			cmd.POST("/kill/:id", cmdController.Kill)
+			cmd.POST("/start/:id", middleware.RequireServiceRole(model.RoleOperator), cmdController.Start)
+			cmd.POST("/stop/:id", middleware.RequireServiceRole(model.RoleOperator), cmdController.Stop)
+			cmd.POST("/restart/:id", middleware.RequireServiceRole(model.RoleOperator), cmdController.Restart)
+			cmd.POST("/force-restart/:id", middleware.RequireServiceRole(model.RoleAdmin), cmdController.ForcedRestart)
+			cmd.POST("/kill/:id", middleware.RequireServiceRole(model.RoleAdmin), cmdController.Kill)
 		}
 
-		// 批量操作
-		batch := api.Group("/batch")
+		// 服务输出
+		output := api.Group("/output")
+		{
+			outputController := controller.NewOutputController()
+			output.GET("/tail/:id", middleware.RequireServiceRole(model.RoleViewer), outputController.Tail)
+			output.GET("/range/:id", middleware.RequireServiceRole(model.RoleViewer), outputController.Range)
+			output.GET("/window/:id", middleware.RequireServiceRole(model.RoleViewer), outputController.Window)
+		}
+
+		// 批量操作，需要 admin
+		batch := api.Group("/batch", middleware.CommandRateLimit())
 		{
 			batchController := controller.NewBatchController()
-			batch.POST("/operation", batchController.BatchOperation)
-			batch.POST("/start-all", batchController.StartAll)
-			batch.POST("/stop-all", batchController.StopAll)
+			batch.POST("/operation", middleware.RequireBatchRole(model.RoleAdmin), batchController.BatchOperation)
+			batch.POST("/start-all", middleware.RequireRole(model.RoleAdmin), batchController.StartAll)
+			batch.POST("/stop-all", middleware.RequireRole(model.RoleAdmin), batchController.StopAll)
 		}
 
 	}
 
-	// 启动服务器
-	addr := "127.0.0.1:" + config.GlobalConfig.Server.Port
-	log.Printf("服务管理工具启动成功，访问地址: http://%s", addr)
-
-	r.Run(addr)
+	// 启动服务器，收到退出信号后优雅退出
+	srv, err := newServer(r)
+	if err != nil {
+		log.Fatalf("服务器启动失败: %v", err)
+	}
+	run(srv, workers)
 }
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"go_service/app/config"
+	"go_service/app/global"
+	"go_service/app/service"
+	"log"
+	"net"
+	"net/http"
+)
+
+// server 按 server 配置创建的 HTTP 服务器，启用 TLS 时使用 HTTPS，证书文件变化时自动重新加载
+type server struct {
+	http     *http.Server
+	reloader *global.TLSReloader
+}
+
+func newServer(handler http.Handler) (*server, error) {
+	serverConfig := config.GlobalConfig.Server
+	security := config.GlobalConfig.Security
+
+	s := &server{
+		http: &http.Server{
+			Addr:         net.JoinHostPort(serverConfig.Host, serverConfig.Port),
+			Handler:      handler,
+			ReadTimeout:  serverConfig.ReadTimeout,
+			WriteTimeout: serverConfig.WriteTimeout,
+			IdleTimeout:  serverConfig.IdleTimeout,
+		},
+	}
+	// 退出时结束实时输出推送，否则长连接会一直占用到超时
+	s.http.RegisterOnShutdown(service.CloseOutputStreams)
+
+	if security.TLSEnabled {
+		reloader, err := global.NewTLSReloader(security)
+		if err != nil {
+			return nil, err
+		}
+		s.reloader = reloader
+		s.http.TLSConfig = reloader.TLSConfig()
+	}
+	return s, nil
+}
+
+// listen 开始监听，调用 shutdown 后返回 nil
+func (s *server) listen() error {
+	var err error
+	if s.reloader == nil {
+		log.Printf("服务管理工具启动成功，访问地址: http://%s", s.http.Addr)
+		err = s.http.ListenAndServe()
+	} else {
+		log.Printf("服务管理工具启动成功，访问地址: https://%s，客户端证书: %s", s.http.Addr, config.GlobalConfig.Security.ClientAuth)
+		err = s.http.ListenAndServeTLS("", "")
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// shutdown 停止接受新请求，等待进行中的请求完成
+func (s *server) shutdown(ctx context.Context) error {
+	if s.reloader != nil {
+		defer s.reloader.Close()
+	}
+	return s.http.Shutdown(ctx)
+}
@@ -0,0 +1,711 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/config"
+	"go_service/app/model"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 告警发送参数
+const (
+	alertQueueSize        = 100 // 待处理的告警和每个渠道待发送的通知数
+	alertSendTimeout      = 10 * time.Second
+	alertMaxBackoff       = time.Minute
+	alertEscalateInterval = 30 * time.Second // 检查是否需要升级通知的间隔
+)
+
+// alerter 当前运行的告警服务，未启动时告警直接丢弃
+var alerter struct {
+	sync.RWMutex
+	service *AlertService
+}
+
+// suppressAlertKey 上下文中标记不发送操作失败告警，自动重启由守护协程自行告警
+type suppressAlertKey struct{}
+
+// withoutOperationAlert 返回不发送操作失败告警的上下文
+func withoutOperationAlert(ctx context.Context) context.Context {
+	return context.WithValue(ctx, suppressAlertKey{}, true)
+}
+
+// sendAlert 异步发送告警，队列满时丢弃
+func sendAlert(event model.AlertEvent) {
+	alerter.RLock()
+	defer alerter.RUnlock()
+	if alerter.service == nil {
+		return
+	}
+	alerter.service.enqueue(event)
+}
+
+// alertCommandFailed 启动、停止等命令执行失败时发送告警，服务已在运行、未运行等状态错误不告警
+func alertCommandFailed(ctx context.Context, serviceId int64, operation string, err error) {
+	if ctx.Value(suppressAlertKey{}) != nil {
+		return
+	}
+	if bizErr, ok := err.(*common.BusinessError); !ok || bizErr.Code != common.ErrCodeCommandFailed {
+		return
+	}
+	sendAlert(model.AlertEvent{
+		Type:      model.AlertOperationFailed,
+		Level:     model.AlertLevelWarning,
+		ServiceId: serviceId,
+		Message:   fmt.Sprintf("%s 失败: %v", operation, err),
+	})
+}
+
+// alertRecovered 服务启动或重启成功，恢复该服务未恢复的问题
+func alertRecovered(serviceId int64, operation string) {
+	sendAlert(model.AlertEvent{
+		Type:      model.AlertServiceRecovered,
+		Level:     model.AlertLevelInfo,
+		ServiceId: serviceId,
+		Message:   operation + " 成功",
+	})
+}
+
+// serviceAlert 构造服务告警
+func serviceAlert(service *model.ServiceModel, alertType, level, message string) model.AlertEvent {
+	return model.AlertEvent{
+		Type:        alertType,
+		Level:       level,
+		ServiceId:   service.Id,
+		ServiceName: service.Name,
+		Group:       service.Group,
+		Tags:        service.TagList(),
+		Message:     message,
+	}
+}
+
+// AlertService 按通知规则发送告警
+// 告警进入队列后由单个协程按顺序处理：跟踪未恢复的问题，按规则匹配渠道，跳过静默窗口内和重复的告警，
+// 问题恢复时通知发送过该问题的规则，持续未恢复时通知升级渠道；没有启用的规则时发送到 monitor.alert_webhook
+// 通知交给各渠道的发送协程，发送失败的重试只阻塞该渠道，不影响告警处理和其他渠道
+type AlertService struct {
+	serviceService      *ServiceService
+	notificationService *NotificationService
+	client              *http.Client
+	queue               chan model.AlertEvent
+	stopChannel         chan struct{}
+	wg                  sync.WaitGroup
+
+	senders  map[int64]*alertSender // 渠道 -> 发送协程，只在处理协程中访问
+	senderWg sync.WaitGroup
+
+	mutex     sync.Mutex
+	incidents map[string]*alertIncident
+	lastSent  map[string]time.Time // 规则:类型:服务 -> 最近一次通知时间
+}
+
+// alertIncident 未恢复的问题
+type alertIncident struct {
+	event     model.AlertEvent // 最近一次触发的告警
+	startedAt time.Time
+	notified  map[int64]map[int64]bool // 规则 -> 已通知的渠道
+	escalated map[int64]bool           // 已升级通知的规则
+}
+
+// alertRoute 一次通知：规则和渠道
+type alertRoute struct {
+	rule     *model.NotificationRuleModel
+	channels []model.NotificationChannelModel
+}
+
+// alertDelivery 待发送到渠道的一条通知
+type alertDelivery struct {
+	rule    string // 规则名称
+	channel model.NotificationChannelModel
+	event   model.AlertEvent
+}
+
+// alertSender 一个渠道的发送队列，按顺序发送，失败时在该协程中退避重试
+type alertSender struct {
+	queue chan alertDelivery
+}
+
+// alertEscalation 待发送的升级通知
+type alertEscalation struct {
+	incident *alertIncident
+	rule     *model.NotificationRuleModel
+	event    model.AlertEvent
+}
+
+func NewAlertService(db *gorm.DB) *AlertService {
+	return &AlertService{
+		serviceService:      NewServiceService(db),
+		notificationService: NewNotificationService(db),
+		client:              &http.Client{Timeout: alertSendTimeout},
+		queue:               make(chan model.AlertEvent, alertQueueSize),
+		stopChannel:         make(chan struct{}),
+		senders:             make(map[int64]*alertSender),
+		incidents:           make(map[string]*alertIncident),
+		lastSent:            make(map[string]time.Time),
+	}
+}
+
+// Start 启动处理协程
+func (a *AlertService) Start() {
+	alerter.Lock()
+	alerter.service = a
+	alerter.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(alertEscalateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event := <-a.queue:
+				a.process(event)
+			case <-ticker.C:
+				a.escalate()
+			case <-a.stopChannel:
+				// 退出前处理队列中剩余的告警
+				for {
+					select {
+					case event := <-a.queue:
+						a.process(event)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止接收告警，处理完队列中的告警并发送各渠道队列中的通知后返回，退出时不再重试
+func (a *AlertService) Stop() {
+	alerter.Lock()
+	if alerter.service == a {
+		alerter.service = nil
+	}
+	alerter.Unlock()
+
+	close(a.stopChannel)
+	a.wg.Wait()
+
+	for _, sender := range a.senders {
+		close(sender.queue)
+	}
+	a.senderWg.Wait()
+}
+
+// Incidents 未恢复的问题，按开始时间排序
+func (a *AlertService) Incidents() []model.AlertIncidentModel {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	list := make([]model.AlertIncidentModel, 0, len(a.incidents))
+	for key, incident := range a.incidents {
+		item := model.AlertIncidentModel{
+			Key:         key,
+			Type:        incident.event.Type,
+			Level:       incident.event.Level,
+			ServiceId:   incident.event.ServiceId,
+			ServiceName: incident.event.ServiceName,
+			Group:       incident.event.Group,
+			Message:     incident.event.Message,
+			StartedAt:   incident.startedAt,
+			UpdatedAt:   incident.event.Time,
+			RuleIds:     []int64{},
+			EscalatedTo: []int64{},
+		}
+		for ruleId := range incident.notified {
+			item.RuleIds = append(item.RuleIds, ruleId)
+		}
+		for ruleId := range incident.escalated {
+			item.EscalatedTo = append(item.EscalatedTo, ruleId)
+		}
+		sort.Slice(item.RuleIds, func(i, j int) bool { return item.RuleIds[i] < item.RuleIds[j] })
+		sort.Slice(item.EscalatedTo, func(i, j int) bool { return item.EscalatedTo[i] < item.EscalatedTo[j] })
+		list = append(list, item)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.Before(list[j].StartedAt) })
+	return list
+}
+
+// CurrentIncidents 当前运行的告警服务中未恢复的问题
+func CurrentIncidents() []model.AlertIncidentModel {
+	alerter.RLock()
+	defer alerter.RUnlock()
+	if alerter.service == nil {
+		return []model.AlertIncidentModel{}
+	}
+	return alerter.service.Incidents()
+}
+
+// enqueue 告警入队，队列满时丢弃
+func (a *AlertService) enqueue(event model.AlertEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case a.queue <- event:
+	default:
+		log.Printf("告警队列已满，丢弃告警: %s %d %s", event.Type, event.ServiceId, event.Message)
+	}
+}
+
+// process 处理一条告警：更新问题状态，按规则发送通知
+func (a *AlertService) process(event model.AlertEvent) {
+	keys, resolving := model.AlertCondition(event)
+	if resolving && !a.hasIncident(keys) {
+		return
+	}
+
+	a.complete(&event)
+	rules, channels, silences, ok := a.routing()
+	if !ok {
+		return
+	}
+	if resolving {
+		a.resolve(event, keys, rules, channels, silences)
+		return
+	}
+
+	var incident *alertIncident
+	if len(keys) > 0 {
+		a.mutex.Lock()
+		incident = a.incidents[keys[0]]
+		if incident == nil {
+			incident = &alertIncident{
+				startedAt: event.Time,
+				notified:  make(map[int64]map[int64]bool),
+				escalated: make(map[int64]bool),
+			}
+			a.incidents[keys[0]] = incident
+		}
+		incident.event = event
+		a.mutex.Unlock()
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(event) || alertSilenced(silences, event) || !a.firstInInterval(rule, event) {
+			continue
+		}
+		route := alertRoute{rule: rule, channels: ruleChannels(rule.ChannelIds, channels)}
+		a.send(route, event)
+		if incident != nil {
+			a.markNotified(incident, route)
+		}
+	}
+}
+
+// hasIncident 是否有未恢复的问题
+func (a *AlertService) hasIncident(keys []string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for _, key := range keys {
+		if _, ok := a.incidents[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve 关闭问题，并通知发送过该问题且开启 send_resolved 的规则
+func (a *AlertService) resolve(event model.AlertEvent, keys []string, rules []model.NotificationRuleModel, channels map[int64]model.NotificationChannelModel, silences []model.NotificationSilenceModel) {
+	for _, key := range keys {
+		a.mutex.Lock()
+		incident := a.incidents[key]
+		delete(a.incidents, key)
+		a.mutex.Unlock()
+		if incident == nil || len(incident.notified) == 0 || alertSilenced(silences, event) {
+			continue
+		}
+
+		resolved := event
+		resolved.Message = fmt.Sprintf("%s，持续 %s", event.Message, event.Time.Sub(incident.startedAt).Round(time.Second))
+		for i := range rules {
+			rule := &rules[i]
+			channelIds, ok := incident.notified[rule.Id]
+			if !ok || !rule.SendResolved {
+				continue
+			}
+			var notified []model.NotificationChannelModel
+			for id := range channelIds {
+				if channel, ok := channels[id]; ok {
+					notified = append(notified, channel)
+				}
+			}
+			a.send(alertRoute{rule: rule, channels: notified}, resolved)
+		}
+	}
+}
+
+// escalate 问题持续时间超过规则的 escalate_after 时通知升级渠道，每个规则只升级一次
+func (a *AlertService) escalate() {
+	a.mutex.Lock()
+	if len(a.incidents) == 0 {
+		a.mutex.Unlock()
+		return
+	}
+	a.mutex.Unlock()
+
+	rules, channels, silences, ok := a.routing()
+	if !ok {
+		return
+	}
+	now := time.Now()
+
+	a.mutex.Lock()
+	var pending []alertEscalation
+	for _, incident := range a.incidents {
+		for i := range rules {
+			rule := &rules[i]
+			if rule.EscalateAfter <= 0 || incident.escalated[rule.Id] || !rule.Matches(incident.event) {
+				continue
+			}
+			duration := now.Sub(incident.startedAt)
+			if duration < time.Duration(rule.EscalateAfter)*time.Second || alertSilenced(silences, incident.event) {
+				continue
+			}
+			incident.escalated[rule.Id] = true
+			event := incident.event
+			event.Escalated = true
+			event.Time = now
+			event.Message = fmt.Sprintf("问题持续 %s 未恢复: %s", duration.Round(time.Second), event.Message)
+			pending = append(pending, alertEscalation{incident: incident, rule: rule, event: event})
+		}
+	}
+	a.mutex.Unlock()
+
+	for _, item := range pending {
+		route := alertRoute{rule: item.rule, channels: ruleChannels(item.rule.EscalateChannelIds, channels)}
+		a.send(route, item.event)
+		a.markNotified(item.incident, route)
+	}
+}
+
+// markNotified 记录问题已通知的规则和渠道，恢复时通知这些渠道
+func (a *AlertService) markNotified(incident *alertIncident, route alertRoute) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if incident.notified[route.rule.Id] == nil {
+		incident.notified[route.rule.Id] = make(map[int64]bool)
+	}
+	for _, channel := range route.channels {
+		incident.notified[route.rule.Id][channel.Id] = true
+	}
+}
+
+// routing 加载通知规则，没有启用的规则时使用 monitor.alert_webhook 作为默认规则(Id 为 0)
+func (a *AlertService) routing() ([]model.NotificationRuleModel, map[int64]model.NotificationChannelModel, []model.NotificationSilenceModel, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rules, channels, silences, err := a.notificationService.loadRouting(ctx)
+	if err != nil {
+		log.Printf("加载通知规则失败: %v", err)
+		return nil, nil, nil, false
+	}
+	if len(rules) > 0 {
+		return rules, channels, silences, true
+	}
+
+	monitor := config.GlobalConfig.Monitor
+	if monitor.AlertWebhook == "" {
+		return nil, nil, nil, false
+	}
+	channels[0] = model.NotificationChannelModel{
+		Name:    "alert_webhook",
+		Type:    monitor.AlertFormat,
+		Url:     monitor.AlertWebhook,
+		Secret:  monitor.AlertSecret,
+		Enabled: true,
+	}
+	rules = []model.NotificationRuleModel{{
+		Name:         "默认",
+		Enabled:      true,
+		ChannelIds:   []int64{0},
+		SendResolved: true,
+	}}
+	return rules, channels, silences, true
+}
+
+// firstInInterval 同一规则下同一服务同类告警在重复间隔内只通知一次
+func (a *AlertService) firstInInterval(rule *model.NotificationRuleModel, event model.AlertEvent) bool {
+	interval := time.Duration(rule.RepeatInterval) * time.Second
+	if interval <= 0 {
+		interval = config.GlobalConfig.Monitor.AlertSilence
+	}
+
+	key := fmt.Sprintf("%d:%s:%d", rule.Id, event.Type, event.ServiceId)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if last, ok := a.lastSent[key]; ok && interval > 0 && event.Time.Sub(last) < interval {
+		return false
+	}
+	a.lastSent[key] = event.Time
+	return true
+}
+
+// complete 补全服务信息和主机名
+func (a *AlertService) complete(event *model.AlertEvent) {
+	if event.ServiceName == "" && event.ServiceId > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if service, err := a.serviceService.GetServiceById(ctx, event.ServiceId); err == nil {
+			event.ServiceName = service.Name
+			event.Group = service.Group
+			event.Tags = service.TagList()
+		}
+		cancel()
+	}
+	if event.Host == "" {
+		event.Host, _ = os.Hostname()
+	}
+}
+
+// send 将通知放入规则各个渠道的发送队列，不等待发送结果，渠道队列满时丢弃
+func (a *AlertService) send(route alertRoute, event model.AlertEvent) {
+	for _, channel := range route.channels {
+		sender := a.sender(channel.Id)
+		select {
+		case sender.queue <- alertDelivery{rule: route.rule.Name, channel: channel, event: event}:
+		default:
+			log.Printf("渠道 %s 的发送队列已满，丢弃告警: %s %d %s", channel.Name, event.Type, event.ServiceId, event.Message)
+		}
+	}
+}
+
+// sender 获取渠道的发送队列，不存在时创建并启动发送协程
+func (a *AlertService) sender(channelId int64) *alertSender {
+	if sender, ok := a.senders[channelId]; ok {
+		return sender
+	}
+	sender := &alertSender{queue: make(chan alertDelivery, alertQueueSize)}
+	a.senders[channelId] = sender
+
+	a.senderWg.Add(1)
+	go func() {
+		defer a.senderWg.Done()
+		for delivery := range sender.queue {
+			if err := a.deliver(delivery.channel, delivery.event); err != nil {
+				log.Printf("规则 %s 发送告警到渠道 %s 失败: %v", delivery.rule, delivery.channel.Name, err)
+			}
+		}
+	}()
+	return sender
+}
+
+// deliver 发送到渠道，失败时按指数退避重试
+func (a *AlertService) deliver(channel model.NotificationChannelModel, event model.AlertEvent) error {
+	monitor := config.GlobalConfig.Monitor
+	backoff := monitor.AlertBackoff
+	if backoff <= 0 {
+		backoff = 2 * time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		err := a.post(channel, event)
+		if err == nil {
+			return nil
+		}
+		if attempt >= monitor.AlertRetries {
+			return fmt.Errorf("已重试 %d 次: %v", attempt, err)
+		}
+		if a.stopping() {
+			return fmt.Errorf("退出时放弃重试: %v", err)
+		}
+		log.Printf("发送告警到渠道 %s 失败，%v 后重试: %v", channel.Name, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-a.stopChannel:
+			// 退出时不再等待重试
+			return fmt.Errorf("退出时放弃重试: %v", err)
+		}
+		backoff *= 2
+		if backoff > alertMaxBackoff {
+			backoff = alertMaxBackoff
+		}
+	}
+}
+
+// stopping 是否正在退出
+func (a *AlertService) stopping() bool {
+	select {
+	case <-a.stopChannel:
+		return true
+	default:
+		return false
+	}
+}
+
+// post 按渠道格式构造请求并发送，聊天机器人接口在响应体中返回错误码
+func (a *AlertService) post(channel model.NotificationChannelModel, event model.AlertEvent) error {
+	format := alertFormat(channel.Type, channel.Url)
+	webhook, body, err := buildAlertRequest(format, channel.Url, channel.Secret, event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if format == model.AlertFormatDingTalk || format == model.AlertFormatWeCom || format == model.AlertFormatFeishu {
+		var result struct {
+			ErrCode *int   `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+			Code    *int   `json:"code"`
+			Msg     string `json:"msg"`
+		}
+		if json.Unmarshal(respBody, &result) == nil {
+			if result.ErrCode != nil && *result.ErrCode != 0 {
+				return fmt.Errorf("errcode %d: %s", *result.ErrCode, result.ErrMsg)
+			}
+			if result.Code != nil && *result.Code != 0 {
+				return fmt.Errorf("code %d: %s", *result.Code, result.Msg)
+			}
+		}
+	}
+	return nil
+}
+
+// alertSilenced 告警是否在静默窗口内
+func alertSilenced(silences []model.NotificationSilenceModel, event model.AlertEvent) bool {
+	for i := range silences {
+		if silences[i].Matches(event, event.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleChannels 规则使用的启用中的渠道
+func ruleChannels(ids []int64, channels map[int64]model.NotificationChannelModel) []model.NotificationChannelModel {
+	var result []model.NotificationChannelModel
+	for _, id := range ids {
+		if channel, ok := channels[id]; ok {
+			result = append(result, channel)
+		}
+	}
+	return result
+}
+
+// alertFormat 告警消息格式，auto 时按 webhook 地址识别
+func alertFormat(format, webhook string) string {
+	if format != "" && format != model.AlertFormatAuto {
+		return format
+	}
+
+	parsed, err := url.Parse(webhook)
+	if err != nil {
+		return model.AlertFormatJSON
+	}
+	switch host := parsed.Hostname(); {
+	case host == "oapi.dingtalk.com":
+		return model.AlertFormatDingTalk
+	case host == "qyapi.weixin.qq.com":
+		return model.AlertFormatWeCom
+	case host == "open.feishu.cn" || host == "open.larksuite.com":
+		return model.AlertFormatFeishu
+	case host == "hooks.slack.com":
+		return model.AlertFormatSlack
+	}
+	return model.AlertFormatJSON
+}
+
+// buildAlertRequest 构造请求地址和请求体，钉钉的签名放在地址中，飞书的签名放在请求体中
+func buildAlertRequest(format, webhook, secret string, event model.AlertEvent) (string, []byte, error) {
+	title := fmt.Sprintf("[go_service] %s: %s", model.AlertTitle(event.Type), event.ServiceName)
+	if event.Escalated {
+		title = "[升级]" + title
+	}
+	lines := []string{
+		"级别: " + event.Level,
+		fmt.Sprintf("服务: %s(%d)", event.ServiceName, event.ServiceId),
+	}
+	if event.Group != "" {
+		lines = append(lines, "分组: "+event.Group)
+	}
+	lines = append(lines,
+		"主机: "+event.Host,
+		"时间: "+event.Time.Format("2006-01-02 15:04:05"),
+		"详情: "+event.Message,
+	)
+
+	var payload interface{}
+	switch format {
+	case model.AlertFormatDingTalk:
+		payload = map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": title,
+				"text":  "### " + title + "\n\n- " + strings.Join(lines, "\n- "),
+			},
+		}
+		if secret != "" {
+			timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			sign := hmacBase64(secret, timestamp+"\n"+secret)
+			separator := "?"
+			if strings.Contains(webhook, "?") {
+				separator = "&"
+			}
+			webhook += separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+		}
+	case model.AlertFormatWeCom:
+		payload = map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"content": "**" + title + "**\n> " + strings.Join(lines, "\n> "),
+			},
+		}
+	case model.AlertFormatFeishu:
+		message := map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": title + "\n" + strings.Join(lines, "\n")},
+		}
+		if secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			message["timestamp"] = timestamp
+			message["sign"] = hmacBase64(timestamp+"\n"+secret, "")
+		}
+		payload = message
+	case model.AlertFormatSlack:
+		payload = map[string]string{"text": "*" + title + "*\n" + strings.Join(lines, "\n")}
+	default:
+		payload = event
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("构造告警消息失败: %v", err)
+	}
+	return webhook, body, nil
+}
+
+// hmacBase64 HmacSHA256 签名的 base64 编码
+func hmacBase64(key, data string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
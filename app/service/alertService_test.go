@@ -0,0 +1,66 @@
+package service
+
+import (
+	"go_service/app/config"
+	"go_service/app/model"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAlertDeliveryPerChannel(t *testing.T) {
+	monitor := config.GlobalConfig.Monitor
+	config.GlobalConfig.Monitor.AlertRetries = 3
+	config.GlobalConfig.Monitor.AlertBackoff = time.Second
+	defer func() { config.GlobalConfig.Monitor = monitor }()
+
+	// 不可用的渠道一直返回错误，正常的渠道记录收到的告警
+	var failed, received atomic.Int32
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failed.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer healthy.Close()
+
+	a := NewAlertService(nil)
+	route := alertRoute{
+		rule: &model.NotificationRuleModel{Name: "测试"},
+		channels: []model.NotificationChannelModel{
+			{Id: 1, Name: "broken", Type: model.AlertFormatJSON, Url: broken.URL},
+			{Id: 2, Name: "healthy", Type: model.AlertFormatJSON, Url: healthy.URL},
+		},
+	}
+
+	// 发送不等待结果，不可用渠道的重试不影响其他渠道和后续告警
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		a.send(route, model.AlertEvent{Type: model.AlertServiceDown, ServiceId: int64(i + 1), Time: time.Now()})
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("放入发送队列耗时 %v，不应等待发送结果", elapsed)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for received.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := received.Load(); count != 3 {
+		t.Fatalf("正常的渠道收到 %d 条告警，期望 3", count)
+	}
+
+	// 退出时放弃等待中的重试，队列中剩余的通知各发送一次
+	start = time.Now()
+	a.Stop()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("退出耗时 %v，不应等待重试", elapsed)
+	}
+	if count := failed.Load(); count != 3 {
+		t.Fatalf("不可用的渠道收到 %d 次请求，期望每条告警各发送一次", count)
+	}
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/config"
+	"go_service/app/model"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 令牌类型
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// 令牌默认有效期，配置未指定时使用
+const (
+	defaultTokenExpiry   = 24 * time.Hour
+	defaultRefreshExpiry = 7 * 24 * time.Hour
+)
+
+// authClaims 令牌内容，Version 与用户的 TokenVersion 不一致时令牌失效
+type authClaims struct {
+	UserId  int64  `json:"uid"`
+	Version int    `json:"ver"`
+	Type    string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// streamTicketExpiry 实时输出连接票据的有效期，票据只用于建立连接，连接建立后不再校验
+const streamTicketExpiry = 30 * time.Second
+
+// streamTicket 已签发的实时输出连接票据，只能用于签发时指定的路径
+type streamTicket struct {
+	userId    int64
+	version   int
+	path      string
+	expiresAt time.Time
+}
+
+// streamTickets 未使用的票据，AuthService 在各中间件和控制器中是独立实例，因此放在包级别
+var streamTickets = struct {
+	sync.Mutex
+	tickets map[string]streamTicket
+}{
+	tickets: make(map[string]streamTicket),
+}
+
+// dummyPasswordHash 用户不存在时同样执行一次 bcrypt 比较，避免通过耗时判断用户名是否存在
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("go_service"), bcrypt.DefaultCost)
+
+type AuthService struct {
+	db *gorm.DB
+}
+
+func NewAuthService(db *gorm.DB) *AuthService {
+	return &AuthService{
+		db: db,
+	}
+}
+
+// EnsureDefaultUser 用户表为空时创建初始管理员，未配置密码时随机生成并打印到日志
+func (a *AuthService) EnsureDefaultUser(ctx context.Context) error {
+	var count int64
+	if err := a.db.WithContext(ctx).Model(&model.UserModel{}).Count(&count).Error; err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	security := config.GlobalConfig.Security
+	username := security.AdminUsername
+	if username == "" {
+		username = "admin"
+	}
+	password := security.AdminPassword
+	generated := password == ""
+	if generated {
+		buf := make([]byte, 12)
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Errorf("生成初始密码失败: %v", err)
+		}
+		password = hex.EncodeToString(buf)
+	}
+
+	request := &model.UserRequest{Username: username, Password: password, Role: model.RoleAdmin}
+	if _, err := NewUserService(a.db).CreateUser(ctx, request); err != nil {
+		return err
+	}
+	if generated {
+		log.Printf("已创建初始管理员 %s，密码: %s，请登录后妥善保管", username, password)
+	} else {
+		log.Printf("已创建初始管理员 %s", username)
+	}
+	return nil
+}
+
+// Login 校验用户名和密码，签发访问令牌和刷新令牌
+func (a *AuthService) Login(ctx context.Context, username, password string) (*model.TokenResponse, error) {
+	var user model.UserModel
+	err := a.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "用户名或密码错误")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "用户名或密码错误")
+	}
+	if !user.Enabled {
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "用户已禁用")
+	}
+
+	now := time.Now()
+	if err := a.db.WithContext(ctx).Model(&user).Update("last_login_at", now).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "更新登录时间失败", err)
+	}
+	user.LastLoginAt = &now
+
+	return a.issueTokens(&user)
+}
+
+// Refresh 使用刷新令牌签发新的令牌
+func (a *AuthService) Refresh(ctx context.Context, refreshToken string) (*model.TokenResponse, error) {
+	user, err := a.verifyToken(ctx, refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	return a.issueTokens(user)
+}
+
+// Logout 递增用户的令牌版本，使该用户已签发的所有令牌失效
+func (a *AuthService) Logout(ctx context.Context, userId int64) error {
+	err := a.db.WithContext(ctx).Model(&model.UserModel{}).
+		Where("id = ?", userId).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+	if err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "退出登录失败", err)
+	}
+	return nil
+}
+
+// Authenticate 校验访问令牌，返回令牌对应的用户
+func (a *AuthService) Authenticate(ctx context.Context, accessToken string) (*model.UserModel, error) {
+	return a.verifyToken(ctx, accessToken, tokenTypeAccess)
+}
+
+// IssueStreamTicket 为实时输出连接签发一次性票据
+// EventSource 和 WebSocket 无法设置请求头，使用票据代替访问令牌放在查询参数中，避免访问令牌写入访问日志
+func (a *AuthService) IssueStreamTicket(user *model.UserModel, path string) (*model.StreamTicketResponse, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("生成票据失败: %v", err)
+	}
+	ticket := hex.EncodeToString(buf)
+
+	now := time.Now()
+	streamTickets.Lock()
+	defer streamTickets.Unlock()
+	for key, issued := range streamTickets.tickets {
+		if now.After(issued.expiresAt) {
+			delete(streamTickets.tickets, key)
+		}
+	}
+	streamTickets.tickets[ticket] = streamTicket{
+		userId:    user.Id,
+		version:   user.TokenVersion,
+		path:      path,
+		expiresAt: now.Add(streamTicketExpiry),
+	}
+	return &model.StreamTicketResponse{Ticket: ticket, ExpiresIn: int64(streamTicketExpiry / time.Second)}, nil
+}
+
+// RedeemStreamTicket 校验并使用票据，返回签发票据的用户，票据无论是否校验成功都只能使用一次
+func (a *AuthService) RedeemStreamTicket(ctx context.Context, ticket, path string) (*model.UserModel, error) {
+	streamTickets.Lock()
+	issued, ok := streamTickets.tickets[ticket]
+	delete(streamTickets.tickets, ticket)
+	streamTickets.Unlock()
+
+	if !ok || issued.path != path || time.Now().After(issued.expiresAt) {
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "票据无效或已过期")
+	}
+
+	var user model.UserModel
+	if err := a.db.WithContext(ctx).First(&user, issued.userId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, common.ErrUnauthorized
+		}
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	// 签发票据后退出登录或被禁用时票据同样失效
+	if !user.Enabled || user.TokenVersion != issued.version {
+		return nil, common.ErrUnauthorized
+	}
+	return &user, nil
+}
+
+// AuthenticateCertificate 客户端证书认证，证书的 CommonName 为用户名，证书链已在TLS握手时由 client_ca_file 校验
+func (a *AuthService) AuthenticateCertificate(ctx context.Context, commonName string) (*model.UserModel, error) {
+	if commonName == "" {
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "客户端证书缺少 CommonName")
+	}
+
+	var user model.UserModel
+	if err := a.db.WithContext(ctx).Where("username = ?", commonName).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "客户端证书对应的用户不存在")
+		}
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	if !user.Enabled {
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "用户已禁用")
+	}
+	return &user, nil
+}
+
+// verifyToken 校验令牌签名、有效期和类型，并确认用户仍然有效且令牌未被注销
+func (a *AuthService) verifyToken(ctx context.Context, token, tokenType string) (*model.UserModel, error) {
+	claims := &authClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return a.secret()
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, common.ErrUnauthorized
+	}
+	if claims.Type != tokenType {
+		return nil, common.NewBusinessError(common.ErrCodeUnauthorized, "令牌类型错误")
+	}
+
+	var user model.UserModel
+	if err := a.db.WithContext(ctx).First(&user, claims.UserId).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, common.ErrUnauthorized
+		}
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	if !user.Enabled || user.TokenVersion != claims.Version {
+		return nil, common.ErrUnauthorized
+	}
+	return &user, nil
+}
+
+// issueTokens 签发访问令牌和刷新令牌
+func (a *AuthService) issueTokens(user *model.UserModel) (*model.TokenResponse, error) {
+	security := config.GlobalConfig.Security
+	accessExpiry := security.TokenExpiry
+	if accessExpiry <= 0 {
+		accessExpiry = defaultTokenExpiry
+	}
+	refreshExpiry := security.RefreshExpiry
+	if refreshExpiry <= 0 {
+		refreshExpiry = defaultRefreshExpiry
+	}
+
+	accessToken, err := a.signToken(user, tokenTypeAccess, accessExpiry)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := a.signToken(user, tokenTypeRefresh, refreshExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessExpiry / time.Second),
+		User:         user,
+	}, nil
+}
+
+// signToken 签发指定类型的令牌
+func (a *AuthService) signToken(user *model.UserModel, tokenType string, expiry time.Duration) (string, error) {
+	secret, err := a.secret()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := authClaims{
+		UserId:  user.Id,
+		Version: user.TokenVersion,
+		Type:    tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.Username,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", fmt.Errorf("签发令牌失败: %v", err)
+	}
+	return signed, nil
+}
+
+// secret 签名密钥，每次读取配置以支持热更新
+func (a *AuthService) secret() ([]byte, error) {
+	secret := config.GlobalConfig.Security.JWTSecret
+	if secret == "" {
+		return nil, fmt.Errorf("未配置JWT密钥")
+	}
+	return []byte(secret), nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"go_service/app/common"
+	"go_service/app/global"
+	"go_service/app/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestAuthService 使用临时 SQLite 数据库的认证服务，返回已创建的用户
+func newTestAuthService(t *testing.T) (*AuthService, *model.UserModel) {
+	t.Helper()
+	db, err := global.InitSqliteClient(filepath.Join(t.TempDir(), "auth.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if _, err := global.MigrateUp(db, "sqlite"); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+	user, err := NewUserService(db).CreateUser(context.Background(), &model.UserRequest{Username: "viewer", Password: "password123", Role: model.RoleViewer})
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return NewAuthService(db), user
+}
+
+func TestStreamTicket(t *testing.T) {
+	a, user := newTestAuthService(t)
+	ctx := context.Background()
+	path := "/api/v1/service/1/logs/stream"
+
+	// issue 签发票据，expire 为 true 时将票据改为已过期
+	issue := func(expire bool) string {
+		result, err := a.IssueStreamTicket(user, path)
+		if err != nil {
+			t.Fatalf("签发票据失败: %v", err)
+		}
+		if expire {
+			streamTickets.Lock()
+			issued := streamTickets.tickets[result.Ticket]
+			issued.expiresAt = time.Now().Add(-time.Second)
+			streamTickets.tickets[result.Ticket] = issued
+			streamTickets.Unlock()
+		}
+		return result.Ticket
+	}
+
+	used := issue(false)
+	if redeemed, err := a.RedeemStreamTicket(ctx, used, path); err != nil || redeemed.Id != user.Id {
+		t.Fatalf("使用票据失败: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		ticket string
+		path   string
+	}{
+		{"重复使用", used, path},
+		{"其他服务", issue(false), "/api/v1/service/2/logs/stream"},
+		{"已过期", issue(true), path},
+		{"不存在", "unknown", path},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := a.RedeemStreamTicket(ctx, tt.ticket, tt.path); errorCode(err) != common.ErrCodeUnauthorized {
+				t.Fatalf("应返回未授权，实际为 %v", err)
+			}
+		})
+	}
+
+	// 退出登录后已签发的票据失效
+	ticket := issue(false)
+	if err := a.Logout(ctx, user.Id); err != nil {
+		t.Fatalf("退出登录失败: %v", err)
+	}
+	if _, err := a.RedeemStreamTicket(ctx, ticket, path); errorCode(err) != common.ErrCodeUnauthorized {
+		t.Fatalf("退出登录后票据应失效，实际为 %v", err)
+	}
+}
@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 	"go_service/app/common"
+	"go_service/app/model"
+	"go_service/app/repository"
 	"go_service/pkg/utils"
 	"os"
-	"os/exec"
-	"strconv"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -17,19 +16,55 @@ import (
 )
 
 type CommandService struct {
-	db             *gorm.DB
 	serviceService *ServiceService
 	logService     *LogService
-	mutex          sync.RWMutex
 	commandTimeout time.Duration
+	executor       Executor
+	ports          PortInspector
+	killer         ProcessKiller
 }
 
+// serviceLocks 每个服务一把操作锁，同一服务的启动、停止等操作依次执行，不同服务的操作可以同时进行
+// CommandService 在各控制器中是独立实例，因此放在包级别
+var serviceLocks = struct {
+	sync.Mutex
+	locks map[int64]*sync.Mutex
+}{
+	locks: make(map[int64]*sync.Mutex),
+}
+
+// serviceLock 获取服务的操作锁
+func serviceLock(serviceId int64) *sync.Mutex {
+	serviceLocks.Lock()
+	defer serviceLocks.Unlock()
+
+	lock, ok := serviceLocks.locks[serviceId]
+	if !ok {
+		lock = &sync.Mutex{}
+		serviceLocks.locks[serviceId] = lock
+	}
+	return lock
+}
+
+// batchConcurrency 批量操作同时操作的服务数
+const batchConcurrency = 5
+
+// portStopTimeout 按端口终止进程时发送 SIGTERM 后等待端口释放的时间，超时后发送 SIGKILL
+const portStopTimeout = 2 * time.Second
+
 func NewCommandService(db *gorm.DB) *CommandService {
+	return NewCommandServiceWithRepository(repository.NewGormServiceRepository(db), repository.NewGormLogRepository(db))
+}
+
+// NewCommandServiceWithRepository 使用指定的服务存储和日志存储创建命令服务
+func NewCommandServiceWithRepository(services repository.ServiceRepository, logs repository.LogRepository) *CommandService {
 	return &CommandService{
-		db:             db,
-		serviceService: NewServiceService(db),
-		logService:     NewLogService(db),
+		serviceService: NewServiceServiceWithRepository(services),
+		logService:     NewLogServiceWithRepository(logs),
 		commandTimeout: 5 * time.Second, // 默认5秒超时
+		executor:       commandExecutor{},
+		ports:          systemPortInspector{},
+		killer:         signalKiller{},
 	}
 }
 
@@ -38,10 +73,31 @@ func (c *CommandService) SetCommandTimeout(timeout time.Duration) {
 	c.commandTimeout = timeout
 }
 
+// SetExecutor 设置执行命令的实现
+func (c *CommandService) SetExecutor(executor Executor) {
+	c.executor = executor
+}
+
+// SetPortInspector 设置查询端口监听状态的实现
+func (c *CommandService) SetPortInspector(ports PortInspector) {
+	c.ports = ports
+}
+
+// SetProcessKiller 设置终止进程的实现
+func (c *CommandService) SetProcessKiller(killer ProcessKiller) {
+	c.killer = killer
+}
+
 // StartService 启动服务
 func (c *CommandService) StartService(ctx context.Context, serviceId int64) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	lock := serviceLock(serviceId)
+	lock.Lock()
+	defer lock.Unlock()
+	end, err := beginOperation(serviceId)
+	if err != nil {
+		return "", err
+	}
+	defer end()
 
 	startTime := time.Now()
 
@@ -59,35 +115,48 @@ func (c *CommandService) StartService(ctx context.Context, serviceId int64) (str
 	}
 
 	// 检查服务是否已在运行
-	port := strconv.Itoa(int(service.Port))
-	if isRunning, _ := utils.IsPortInUse(port); isRunning {
+	if c.ports.IsListening(service.Port) || c.isServiceRunning(service) {
 		err := common.NewBusinessError(common.ErrCodeServiceRunning, "服务已在运行")
 		c.logService.LogOperation(ctx, serviceId, "start", "failed", "", err.Error(), time.Since(startTime))
 		return "", err
 	}
 
 	// 执行启动命令
-	output, err := c.executeCommand(ctx, service.CmdStart, service.Dir)
+	output, process, err := c.launchService(ctx, service)
 	if err != nil {
 		c.logService.LogOperation(ctx, serviceId, "start", "failed", output, err.Error(), time.Since(startTime))
-		return output, common.WrapError(common.ErrCodeCommandFailed, "启动服务失败", err)
+		err = common.WrapError(common.ErrCodeCommandFailed, "启动服务失败", err)
+		alertCommandFailed(ctx, serviceId, "start", err)
+		return output, err
 	}
 
-	// 等待服务启动完成
-	if err := c.waitForServiceStart(port, 3*time.Second); err != nil {
+	// 等待服务就绪
+	err = c.waitForServiceReady(ctx, service, process, output)
+	output = launchOutput(output, process)
+	if err != nil {
 		c.logService.LogOperation(ctx, serviceId, "start", "failed", output, err.Error(), time.Since(startTime))
-		return output, common.WrapError(common.ErrCodeCommandFailed, "服务启动超时", err)
+		err = common.WrapError(common.ErrCodeCommandFailed, "服务启动超时", err)
+		alertCommandFailed(ctx, serviceId, "start", err)
+		return output, err
 	}
 
 	// 记录成功日志
+	clearManualStop(serviceId)
 	c.logService.LogOperation(ctx, serviceId, "start", "success", output, "", time.Since(startTime))
+	alertRecovered(serviceId, "start")
 	return output, nil
 }
 
 // StopService 停止服务
 func (c *CommandService) StopService(ctx context.Context, serviceId int64) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	lock := serviceLock(serviceId)
+	lock.Lock()
+	defer lock.Unlock()
+	end, err := beginOperation(serviceId)
+	if err != nil {
+		return "", err
+	}
+	defer end()
 
 	startTime := time.Now()
 
@@ -98,8 +167,7 @@ func (c *CommandService) StopService(ctx context.Context, serviceId int64) (stri
 		return "", err
 	}
 
-	port := strconv.Itoa(int(service.Port))
-	if isRunning, _ := utils.IsPortInUse(port); !isRunning {
+	if !c.isServiceRunning(service) {
 		err := common.NewBusinessError(common.ErrCodeServiceStopped, "服务未运行")
 		c.logService.LogOperation(ctx, serviceId, "stop", "failed", "", err.Error(), time.Since(startTime))
 		return "", err
@@ -108,12 +176,21 @@ func (c *CommandService) StopService(ctx context.Context, serviceId int64) (stri
 	var output string
 	var finalErr error
 
+	// 标记为主动停止，避免被自动重启；停止失败时再清除并告警
+	markManualStop(serviceId)
+	defer func() {
+		if finalErr != nil {
+			clearManualStop(serviceId)
+			alertCommandFailed(ctx, serviceId, "stop", finalErr)
+		}
+	}()
+
 	// 优先使用停止命令
 	if service.CmdStop != "" {
-		output, err = c.executeCommand(ctx, service.CmdStop, service.Dir)
+		output, err = c.executeServiceCommand(ctx, service, service.CmdStop)
 		if err != nil {
 			// 停止命令失败，尝试强制终止
-			killOutput, killErr := utils.Kill(port)
+			killOutput, killErr := c.terminateService(service, false)
 			if killErr != nil {
 				finalErr = common.WrapError(common.ErrCodeCommandFailed, "停止服务失败", err)
 				c.logService.LogOperation(ctx, serviceId, "stop", "failed", output, finalErr.Error(), time.Since(startTime))
@@ -123,7 +200,7 @@ func (c *CommandService) StopService(ctx context.Context, serviceId int64) (stri
 		}
 	} else {
 		// 没有停止命令，直接强制终止
-		output, err = utils.Kill(port)
+		output, err = c.terminateService(service, false)
 		if err != nil {
 			finalErr = common.WrapError(common.ErrCodeCommandFailed, "强制终止服务失败", err)
 			c.logService.LogOperation(ctx, serviceId, "stop", "failed", output, finalErr.Error(), time.Since(startTime))
@@ -132,7 +209,7 @@ func (c *CommandService) StopService(ctx context.Context, serviceId int64) (stri
 	}
 
 	// 等待服务停止完成
-	if err := c.waitForServiceStop(port, 3*time.Second); err != nil {
+	if err := c.waitForServiceStop(service, 3*time.Second); err != nil {
 		finalErr = common.WrapError(common.ErrCodeCommandFailed, "服务停止超时", err)
 		c.logService.LogOperation(ctx, serviceId, "stop", "failed", output, finalErr.Error(), time.Since(startTime))
 		return output, finalErr
@@ -145,8 +222,22 @@ func (c *CommandService) StopService(ctx context.Context, serviceId int64) (stri
 
 // RestartService 重启服务
 func (c *CommandService) RestartService(ctx context.Context, serviceId int64) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	startTime := time.Now()
+	output, err := c.restartService(ctx, serviceId)
+	c.logResult(ctx, serviceId, "restart", output, err, startTime)
+	return output, err
+}
+
+// restartService 重启服务，不记录操作日志
+func (c *CommandService) restartService(ctx context.Context, serviceId int64) (string, error) {
+	lock := serviceLock(serviceId)
+	lock.Lock()
+	defer lock.Unlock()
+	end, err := beginOperation(serviceId)
+	if err != nil {
+		return "", err
+	}
+	defer end()
 
 	// 获取服务信息
 	service, err := c.serviceService.GetServiceById(ctx, serviceId)
@@ -154,35 +245,34 @@ func (c *CommandService) RestartService(ctx context.Context, serviceId int64) (s
 		return "", err
 	}
 
-	port := strconv.Itoa(int(service.Port))
-	if isRunning, _ := utils.IsPortInUse(port); !isRunning {
+	if !c.isServiceRunning(service) {
 		return "", common.NewBusinessError(common.ErrCodeServiceStopped, "服务未运行")
 	}
 
 	var output string
-	// 优先使用重启命令
-	if service.CmdRestart != "" {
-		output, err = c.executeCommand(ctx, service.CmdRestart, service.Dir)
+	// 优先使用重启命令，托管模式的进程由 go_service 持有，始终先停止再启动
+	if service.CmdRestart != "" && !isManaged(service) {
+		output, err = c.executeServiceCommand(ctx, service, service.CmdRestart)
 		if err != nil {
 			return output, common.WrapError(common.ErrCodeCommandFailed, "重启服务失败", err)
 		}
 	} else {
 		// 没有重启命令，先停止再启动
-		c.mutex.Unlock() // 临时释放锁避免死锁
+		lock.Unlock() // 临时释放锁避免死锁
 		stopOutput, err := c.StopService(ctx, serviceId)
 		if err != nil {
-			c.mutex.Lock()
+			lock.Lock()
 			return stopOutput, err
 		}
 
 		// 等待端口释放
-		if err := utils.WaitForPortFree(port, 5*time.Second); err != nil {
-			c.mutex.Lock()
+		if err := c.waitForPortFree(service.Port, 5*time.Second); err != nil {
+			lock.Lock()
 			return stopOutput, common.WrapError(common.ErrCodeCommandFailed, "等待端口释放失败", err)
 		}
 
 		startOutput, err := c.StartService(ctx, serviceId)
-		c.mutex.Lock()
+		lock.Lock()
 		if err != nil {
 			return startOutput, err
 		}
@@ -194,8 +284,22 @@ func (c *CommandService) RestartService(ctx context.Context, serviceId int64) (s
 
 // ForceRestartService 强制重启服务
 func (c *CommandService) ForceRestartService(ctx context.Context, serviceId int64) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	startTime := time.Now()
+	output, err := c.forceRestartService(ctx, serviceId)
+	c.logResult(ctx, serviceId, "force_restart", output, err, startTime)
+	return output, err
+}
+
+// forceRestartService 强制重启服务，不记录操作日志
+func (c *CommandService) forceRestartService(ctx context.Context, serviceId int64) (string, error) {
+	lock := serviceLock(serviceId)
+	lock.Lock()
+	defer lock.Unlock()
+	end, err := beginOperation(serviceId)
+	if err != nil {
+		return "", err
+	}
+	defer end()
 
 	// 获取服务信息
 	service, err := c.serviceService.GetServiceById(ctx, serviceId)
@@ -207,33 +311,35 @@ func (c *CommandService) ForceRestartService(ctx context.Context, serviceId int6
 		return "", common.NewBusinessError(common.ErrCodeInvalidParam, "启动命令未配置")
 	}
 
-	port := strconv.Itoa(int(service.Port))
 	var stopOutput string
 
 	// 如果服务正在运行，强制终止
-	if isRunning, _ := utils.IsPortInUse(port); isRunning {
-		stopOutput, err = utils.Kill(port)
+	if c.ports.IsListening(service.Port) || c.isServiceRunning(service) {
+		stopOutput, err = c.terminateService(service, true)
 		if err != nil {
 			return stopOutput, common.WrapError(common.ErrCodeCommandFailed, "强制终止服务失败", err)
 		}
 
 		// 等待端口释放
-		if err := utils.WaitForPortFree(port, 5*time.Second); err != nil {
+		if err := c.waitForPortFree(service.Port, 5*time.Second); err != nil {
 			return stopOutput, common.WrapError(common.ErrCodeCommandFailed, "等待端口释放失败", err)
 		}
 	}
 
 	// 启动服务
-	startOutput, err := c.executeCommand(ctx, service.CmdStart, service.Dir)
+	startOutput, process, err := c.launchService(ctx, service)
 	if err != nil {
 		return startOutput, common.WrapError(common.ErrCodeCommandFailed, "启动服务失败", err)
 	}
 
-	// 等待服务启动完成
-	if err := c.waitForServiceStart(port, 3*time.Second); err != nil {
+	// 等待服务就绪
+	err = c.waitForServiceReady(ctx, service, process, startOutput)
+	startOutput = launchOutput(startOutput, process)
+	if err != nil {
 		return startOutput, common.WrapError(common.ErrCodeCommandFailed, "服务启动超时", err)
 	}
 
+	clearManualStop(serviceId)
 	if stopOutput != "" {
 		return fmt.Sprintf("强制终止输出:\n%s\n启动输出:\n%s", stopOutput, startOutput), nil
 	}
@@ -242,8 +348,22 @@ func (c *CommandService) ForceRestartService(ctx context.Context, serviceId int6
 
 // KillService 强制终止服务
 func (c *CommandService) KillService(ctx context.Context, serviceId int64) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	startTime := time.Now()
+	output, err := c.killService(ctx, serviceId)
+	c.logResult(ctx, serviceId, "kill", output, err, startTime)
+	return output, err
+}
+
+// killService 强制终止服务，不记录操作日志
+func (c *CommandService) killService(ctx context.Context, serviceId int64) (string, error) {
+	lock := serviceLock(serviceId)
+	lock.Lock()
+	defer lock.Unlock()
+	end, err := beginOperation(serviceId)
+	if err != nil {
+		return "", err
+	}
+	defer end()
 
 	// 获取服务信息
 	service, err := c.serviceService.GetServiceById(ctx, serviceId)
@@ -251,26 +371,42 @@ func (c *CommandService) KillService(ctx context.Context, serviceId int64) (stri
 		return "", err
 	}
 
-	port := strconv.Itoa(int(service.Port))
-	if isRunning, _ := utils.IsPortInUse(port); !isRunning {
+	if !c.isServiceRunning(service) {
 		return "", common.NewBusinessError(common.ErrCodeServiceStopped, "服务未运行")
 	}
 
 	// 强制终止进程
-	output, err := utils.Kill(port)
+	markManualStop(serviceId)
+	output, err := c.terminateService(service, true)
 	if err != nil {
+		clearManualStop(serviceId)
 		return output, common.WrapError(common.ErrCodeCommandFailed, "强制终止服务失败", err)
 	}
 
 	return output, nil
 }
 
+// logResult 按操作结果记录操作日志
+func (c *CommandService) logResult(ctx context.Context, serviceId int64, operation, output string, err error, startTime time.Time) {
+	if err != nil {
+		c.logService.LogOperation(ctx, serviceId, operation, "failed", output, err.Error(), time.Since(startTime))
+		alertCommandFailed(ctx, serviceId, operation, err)
+		return
+	}
+	c.logService.LogOperation(ctx, serviceId, operation, "success", output, "", time.Since(startTime))
+	if operation != "kill" {
+		alertRecovered(serviceId, operation)
+	}
+}
+
 // BatchOperation 批量操作服务 - 优化版本
-func (c *CommandService) BatchOperation(ctx context.Context, serviceIds []int64, operation string) []map[string]interface{} {
+// 返回每个服务的结果和批次ID，这一批操作的日志记录相同的批次ID
+func (c *CommandService) BatchOperation(ctx context.Context, serviceIds []int64, operation string) ([]map[string]interface{}, string) {
 	results := make([]map[string]interface{}, len(serviceIds))
+	ctx, batchId := withBatch(ctx)
 
-	// 限制并发数量，避免系统过载
-	maxConcurrency := 5
+	// 限制并发数量，避免系统过载；同一服务的操作由服务的操作锁保证依次执行
+	maxConcurrency := batchConcurrency
 	if len(serviceIds) < maxConcurrency {
 		maxConcurrency = len(serviceIds)
 	}
@@ -311,6 +447,8 @@ func (c *CommandService) BatchOperation(ctx context.Context, serviceIds []int64,
 				output, err = c.StopService(opCtx, id)
 			case "restart":
 				output, err = c.RestartService(opCtx, id)
+			case "force_restart":
+				output, err = c.ForceRestartService(opCtx, id)
 			case "kill":
 				output, err = c.KillService(opCtx, id)
 			default:
@@ -339,95 +477,185 @@ func (c *CommandService) BatchOperation(ctx context.Context, serviceIds []int64,
 	}
 
 	wg.Wait()
-	return results
+	return results, batchId
 }
 
 // executeCommand 执行命令 - 安全优化版本
 func (c *CommandService) executeCommand(ctx context.Context, command, workDir string) (string, error) {
-	if command == "" {
-		return "", fmt.Errorf("命令不能为空")
-	}
+	// 创建带超时的上下文
+	cmdCtx, cancel := context.WithTimeout(ctx, c.commandTimeout)
+	defer cancel()
 
-	// 验证命令安全性
-	if err := utils.ValidateCommand(command); err != nil {
-		return "", fmt.Errorf("命令安全验证失败: %v", err)
+	// 执行命令
+	output, err := c.executor.Run(cmdCtx, command, workDir)
+	if err != nil {
+		// 检查是否是超时错误
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			return output, fmt.Errorf("命令执行超时: %v", err)
+		}
+		return output, fmt.Errorf("命令执行失败: %v", err)
 	}
 
-	// 验证工作目录
-	if workDir != "" {
-		if err := utils.IsValidDirectory(workDir); err != nil {
-			return "", fmt.Errorf("工作目录验证失败: %v", err)
+	return output, nil
+}
+
+// launchService 启动服务：托管模式由 go_service 启动并持有进程，否则执行启动命令
+func (c *CommandService) launchService(ctx context.Context, service *model.ServiceModel) (string, *managedProcess, error) {
+	if isManaged(service) {
+		process, err := spawnManagedProcess(service)
+		if err != nil {
+			return "", nil, err
 		}
+		return fmt.Sprintf("托管进程已启动，PID: %d", process.pid), process, nil
 	}
 
-	// 清理命令
-	command = utils.SanitizeCommand(command)
+	output, err := c.executeServiceCommand(ctx, service, service.CmdStart)
+	return output, nil, err
+}
 
-	// 创建带超时的上下文
-	cmdCtx, cancel := context.WithTimeout(ctx, c.commandTimeout)
-	defer cancel()
+// executeServiceCommand 执行服务的启动、停止或重启命令，命令及其输出写入服务输出日志
+func (c *CommandService) executeServiceCommand(ctx context.Context, service *model.ServiceModel, command string) (string, error) {
+	recordOutputEvent(service.Id, "执行命令: %s", command)
+	output, err := c.executeCommand(ctx, command, service.Dir)
+	recordOutput(service.Id, OutputStreamCommand, output)
+	if err != nil {
+		recordOutputEvent(service.Id, "命令执行失败: %v", err)
+	}
+	return output, err
+}
 
-	var cmd *exec.Cmd
+// launchOutput 合并启动信息与托管进程目前的输出
+func launchOutput(output string, process *managedProcess) string {
+	if process == nil {
+		return output
+	}
+	if processOutput := process.output.String(); processOutput != "" {
+		return fmt.Sprintf("%s\n%s", output, processOutput)
+	}
+	return output
+}
 
-	// 根据命令类型选择执行方式
-	if strings.Contains(command, "&&") || strings.Contains(command, "||") || strings.Contains(command, ";") {
-		// 复杂命令，使用bash执行
-		cmd = exec.CommandContext(cmdCtx, "bash", "-c", command)
-	} else {
-		// 简单命令，直接分割参数
-		args := strings.Fields(command)
-		if len(args) == 0 {
-			return "", fmt.Errorf("无效的命令")
+// terminateService 终止服务进程：托管进程向整个进程组发送信号，否则按端口查找进程终止
+func (c *CommandService) terminateService(service *model.ServiceModel, force bool) (string, error) {
+	if process := getRunningManagedProcess(service.Id); process != nil {
+		return process.terminate(force)
+	}
+	return c.killPortProcess(service.Port, force)
+}
+
+// killPortProcess 终止监听端口的进程，force 为 false 时先发送 SIGTERM，端口未释放再发送 SIGKILL
+func (c *CommandService) killPortProcess(port int64, force bool) (string, error) {
+	if err := utils.ValidatePort(port); err != nil {
+		return "", err
+	}
+	pid, err := c.ports.ListenerPid(port)
+	if err != nil {
+		return "", err
+	}
+	// 检查是否为系统关键进程
+	if pid == 1 || pid == os.Getpid() {
+		return "", fmt.Errorf("不能终止系统关键进程")
+	}
+
+	if !force {
+		if err := c.killer.Kill(pid, syscall.SIGTERM); err == nil {
+			if c.waitForPortFree(port, portStopTimeout) == nil {
+				return fmt.Sprintf("进程 %d 已通过 SIGTERM 停止", pid), nil
+			}
+		}
+	}
+
+	if err := c.killer.Kill(pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return "", fmt.Errorf("终止进程 %d 失败: %v", pid, err)
+	}
+	return fmt.Sprintf("进程 %d 已通过 SIGKILL 终止", pid), nil
+}
+
+// isServiceRunning 判断服务是否运行，未托管的服务通过端口查询判断
+func (c *CommandService) isServiceRunning(service *model.ServiceModel) bool {
+	return isServiceListening(service, c.ports)
+}
+
+// waitForPortFree 等待端口释放
+func (c *CommandService) waitForPortFree(port int64, timeout time.Duration) error {
+	start := time.Now()
+	for time.Since(start) < timeout {
+		if !c.ports.IsListening(port) {
+			return nil
 		}
-		cmd = exec.CommandContext(cmdCtx, args[0], args[1:]...)
+		time.Sleep(100 * time.Millisecond)
 	}
+	return fmt.Errorf("等待端口释放超时")
+}
 
-	// 设置工作目录
-	if workDir != "" {
-		cmd.Dir = workDir
+// waitForServiceReady 等待服务就绪：先等待启动探针，再等待就绪探针；未配置就绪探针时沿用端口检测
+// 托管进程在就绪前退出时立即返回，并终止残留的进程组
+func (c *CommandService) waitForServiceReady(ctx context.Context, service *model.ServiceModel, process *managedProcess, output string) error {
+	readyCtx := ctx
+	outputFunc := func() string { return output }
+	if process != nil {
+		var cancel context.CancelFunc
+		readyCtx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-process.done:
+				cancel()
+			case <-readyCtx.Done():
+			}
+		}()
+		outputFunc = process.output.String
 	}
 
-	// 设置子进程独立于父进程
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true, // 创建新进程组
+	err := c.waitForProbes(readyCtx, service, outputFunc)
+	if err != nil && process != nil {
+		if process.isExited() {
+			return fmt.Errorf("服务就绪前%s", process.exitDescription())
+		}
+		process.terminate(true)
 	}
+	return err
+}
 
-	// 设置环境变量限制
-	cmd.Env = append(os.Environ(),
-		"PATH=/usr/local/bin:/usr/bin:/bin", // 限制PATH
-		"SHELL=/bin/bash",                   // 固定shell
-	)
+// waitForProbes 依次等待启动探针和就绪探针
+func (c *CommandService) waitForProbes(ctx context.Context, service *model.ServiceModel, output func() string) error {
+	if service.StartupProbe != nil {
+		if err := waitForProbe(ctx, service.StartupProbe, service, output); err != nil {
+			return fmt.Errorf("启动探针失败: %v", err)
+		}
+	}
 
-	// 执行命令
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// 检查是否是超时错误
-		if cmdCtx.Err() == context.DeadlineExceeded {
-			return string(output), fmt.Errorf("命令执行超时: %v", err)
+	if service.ReadinessProbe != nil {
+		if err := waitForProbe(ctx, service.ReadinessProbe, service, output); err != nil {
+			return fmt.Errorf("就绪探针失败: %v", err)
 		}
-		return string(output), fmt.Errorf("命令执行失败: %v", err)
+		return nil
 	}
 
-	return string(output), nil
+	return c.waitForServiceStart(ctx, service.Port, 3*time.Second)
 }
 
 // waitForServiceStart 等待服务启动
-func (c *CommandService) waitForServiceStart(port string, timeout time.Duration) error {
+func (c *CommandService) waitForServiceStart(ctx context.Context, port int64, timeout time.Duration) error {
 	start := time.Now()
 	for time.Since(start) < timeout {
-		if isRunning, _ := utils.IsPortInUse(port); isRunning {
+		if c.ports.IsListening(port) {
 			return nil
 		}
-		time.Sleep(500 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("等待服务启动被取消")
+		case <-time.After(500 * time.Millisecond):
+		}
 	}
 	return fmt.Errorf("等待服务启动超时")
 }
 
-// waitForServiceStop 等待服务停止
-func (c *CommandService) waitForServiceStop(port string, timeout time.Duration) error {
+// waitForServiceStop 等待服务停止，托管进程需进程退出且端口释放
+func (c *CommandService) waitForServiceStop(service *model.ServiceModel, timeout time.Duration) error {
 	start := time.Now()
 	for time.Since(start) < timeout {
-		if isRunning, _ := utils.IsPortInUse(port); !isRunning {
+		if !c.ports.IsListening(service.Port) && !c.isServiceRunning(service) {
 			return nil
 		}
 		time.Sleep(500 * time.Millisecond)
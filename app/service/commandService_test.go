@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go_service/app/common"
+	"go_service/app/config"
+	"go_service/app/model"
+	"go_service/app/repository"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// 服务输出日志写入临时目录
+	dir, err := os.MkdirTemp("", "go_service_test")
+	if err != nil {
+		panic(err)
+	}
+	config.GlobalConfig = &config.Config{Log: config.LogConfig{ServiceDir: dir}}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestCommandService 使用内存存储和编排的进程运行环境的命令服务
+func newTestCommandService(t *testing.T) (*CommandService, *fakeRuntime) {
+	t.Helper()
+	services, logs := repository.NewMemoryRepositories()
+	runtime := newFakeRuntime()
+	c := NewCommandServiceWithRepository(services, logs)
+	c.SetExecutor(runtime)
+	c.SetPortInspector(runtime)
+	c.SetProcessKiller(runtime)
+	c.SetCommandTimeout(100 * time.Millisecond)
+	t.Cleanup(c.logService.Close)
+	return c, runtime
+}
+
+// loggedOperations 服务的操作日志，按操作名称索引；会先关闭日志服务，确保异步日志已写入
+func loggedOperations(t *testing.T, c *CommandService, serviceId int64) map[string]model.ServiceLog {
+	t.Helper()
+	c.logService.Close()
+	entries, err := c.logService.GetServiceLogs(context.Background(), serviceId, 0)
+	if err != nil {
+		t.Fatalf("查询操作日志失败: %v", err)
+	}
+	result := make(map[string]model.ServiceLog)
+	for _, entry := range entries {
+		result[entry.Operation] = entry
+	}
+	return result
+}
+
+func TestStopServiceFallback(t *testing.T) {
+	const port = 18280
+	tests := []struct {
+		name       string
+		cmdStop    string
+		stop       fakeCommand
+		ignoreTerm bool
+		killErr    error
+		code       int
+		output     string
+		signals    []syscall.Signal
+	}{
+		{"停止命令成功", "./api stop", fakeCommand{output: "stopped", release: port}, false, nil, 0, "stopped", nil},
+		{"停止命令失败后强制终止", "./api stop", fakeCommand{output: "no such pid", err: errors.New("exit status 1")}, false, nil, 0, "停止命令失败，已强制终止", []syscall.Signal{syscall.SIGTERM}},
+		{"停止命令超时后强制终止", "./api stop", fakeCommand{delay: time.Second}, false, nil, 0, "停止命令失败，已强制终止", []syscall.Signal{syscall.SIGTERM}},
+		{"忽略 SIGTERM 时发送 SIGKILL", "./api stop", fakeCommand{err: errors.New("exit status 1")}, true, nil, 0, "SIGKILL", []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL}},
+		{"未配置停止命令时直接终止", "", fakeCommand{}, false, nil, 0, "SIGTERM", []syscall.Signal{syscall.SIGTERM}},
+		{"强制终止失败", "./api stop", fakeCommand{err: errors.New("exit status 1")}, false, syscall.EPERM, common.ErrCodeCommandFailed, "", []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, runtime := newTestCommandService(t)
+			ctx := context.Background()
+			service := testService("api", port)
+			service.CmdStop = tt.cmdStop
+			mustCreate(t, c.serviceService, service)
+			runtime.script(tt.cmdStop, tt.stop)
+			pid := runtime.listen(port)
+			runtime.ignoreTerm[pid] = tt.ignoreTerm
+			runtime.killErr = tt.killErr
+
+			output, err := c.StopService(ctx, service.Id)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("错误码为 %d(%v)，期望 %d", code, err, tt.code)
+			}
+			if !strings.Contains(output, tt.output) {
+				t.Fatalf("输出 %q 不包含 %q", output, tt.output)
+			}
+			signals := runtime.sentSignals()
+			if len(signals) != len(tt.signals) {
+				t.Fatalf("发送的信号为 %v，期望 %v", signals, tt.signals)
+			}
+			for i, signal := range signals {
+				if signal.pid != pid || signal.sig != tt.signals[i] {
+					t.Fatalf("发送的信号为 %v，期望向进程 %d 发送 %v", signals, pid, tt.signals)
+				}
+			}
+
+			stopped := tt.code == 0
+			if runtime.IsListening(port) == stopped {
+				t.Fatalf("停止后端口监听状态为 %v", runtime.IsListening(port))
+			}
+			if isManualStopped(service.Id) != stopped {
+				t.Fatalf("主动停止标记为 %v，期望 %v", isManualStopped(service.Id), stopped)
+			}
+			status := "success"
+			if !stopped {
+				status = "failed"
+			}
+			if entry := loggedOperations(t, c, service.Id)["stop"]; entry.Status != status {
+				t.Fatalf("stop 日志状态为 %q，期望 %q", entry.Status, status)
+			}
+		})
+	}
+}
+
+func TestRestartService(t *testing.T) {
+	const port = 18281
+	tests := []struct {
+		name       string
+		cmdRestart string
+		running    bool
+		code       int
+		executed   []string
+		operations []string
+	}{
+		{"使用重启命令", "./api restart", true, 0, []string{"./api restart"}, []string{"create", "restart"}},
+		{"未配置重启命令时先停止再启动", "", true, 0, []string{"./api stop", "./api"}, []string{"create", "stop", "start", "restart"}},
+		{"服务未运行", "./api restart", false, common.ErrCodeServiceStopped, nil, []string{"create", "restart"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, runtime := newTestCommandService(t)
+			ctx := context.Background()
+			service := testService("api", port)
+			service.CmdStop = "./api stop"
+			service.CmdRestart = tt.cmdRestart
+			mustCreate(t, c.serviceService, service)
+			runtime.script("./api", fakeCommand{output: "started", listen: port})
+			runtime.script("./api stop", fakeCommand{output: "stopped", release: port})
+			runtime.script("./api restart", fakeCommand{output: "restarted"})
+			if tt.running {
+				runtime.listen(port)
+			}
+
+			output, err := c.RestartService(ctx, service.Id)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("错误码为 %d(%v)，期望 %d", code, err, tt.code)
+			}
+			executed := runtime.executedCommands()
+			if strings.Join(executed, ",") != strings.Join(tt.executed, ",") {
+				t.Fatalf("执行的命令为 %v，期望 %v", executed, tt.executed)
+			}
+			if tt.code != 0 {
+				if entry := loggedOperations(t, c, service.Id)["restart"]; entry.Status != "failed" {
+					t.Fatalf("restart 日志状态为 %q，期望 failed", entry.Status)
+				}
+				return
+			}
+
+			if tt.cmdRestart == "" && !(strings.Contains(output, "停止输出:\nstopped") && strings.Contains(output, "启动输出:\nstarted")) {
+				t.Fatalf("先停止再启动的输出应包含停止和启动的输出，实际为 %q", output)
+			}
+			if !runtime.IsListening(port) {
+				t.Fatalf("重启后服务未运行")
+			}
+			if len(runtime.sentSignals()) != 0 {
+				t.Fatalf("重启不应终止进程，实际发送了 %v", runtime.sentSignals())
+			}
+			logged := loggedOperations(t, c, service.Id)
+			if len(logged) != len(tt.operations) {
+				t.Fatalf("记录的操作为 %v，期望 %v", logged, tt.operations)
+			}
+			for _, operation := range tt.operations {
+				if entry, ok := logged[operation]; !ok || entry.Status != "success" {
+					t.Fatalf("%s 日志为 %+v，期望成功", operation, entry)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandTimeouts(t *testing.T) {
+	const port = 18282
+	tests := []struct {
+		name      string
+		operation string
+		running   bool
+		command   fakeCommand
+		message   string
+		minDelay  time.Duration
+	}{
+		{"启动命令超时", "start", false, fakeCommand{delay: time.Second, listen: port}, "命令执行超时", 0},
+		{"启动后端口未监听", "start", false, fakeCommand{output: "started"}, "服务启动超时", 3 * time.Second},
+		{"停止后端口未释放", "stop", true, fakeCommand{output: "stopped"}, "服务停止超时", 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, runtime := newTestCommandService(t)
+			ctx := context.Background()
+			service := testService("api", port)
+			service.CmdStop = "./api stop"
+			mustCreate(t, c.serviceService, service)
+			runtime.script("./api", tt.command)
+			runtime.script("./api stop", tt.command)
+			if tt.running {
+				runtime.listen(port)
+			}
+
+			start := time.Now()
+			var err error
+			if tt.operation == "start" {
+				_, err = c.StartService(ctx, service.Id)
+			} else {
+				_, err = c.StopService(ctx, service.Id)
+			}
+			elapsed := time.Since(start)
+			if errorCode(err) != common.ErrCodeCommandFailed || !strings.Contains(err.Error(), tt.message) {
+				t.Fatalf("错误为 %v，期望包含 %q", err, tt.message)
+			}
+			if elapsed < tt.minDelay || elapsed > tt.minDelay+time.Second {
+				t.Fatalf("耗时 %v，期望约 %v", elapsed, tt.minDelay)
+			}
+			if entry := loggedOperations(t, c, service.Id)[tt.operation]; entry.Status != "failed" || !strings.Contains(entry.Error, tt.message) {
+				t.Fatalf("%s 日志为 %+v，期望记录失败原因", tt.operation, entry)
+			}
+		})
+	}
+}
+
+func TestBatchOperationConcurrency(t *testing.T) {
+	c, runtime := newTestCommandService(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for i := 0; i < 8; i++ {
+		service := mustCreate(t, c.serviceService, testService("api"+strings.Repeat("x", i), int64(18290+i)))
+		runtime.script(service.CmdStart, fakeCommand{output: "started", delay: 100 * time.Millisecond, listen: service.Port})
+		ids = append(ids, service.Id)
+	}
+	// 不存在的服务只影响自己的结果
+	ids = append(ids[:3], append([]int64{999}, ids[3:]...)...)
+
+	results, batchId := c.BatchOperation(ctx, ids, "start")
+	if len(results) != len(ids) || batchId == "" {
+		t.Fatalf("返回 %d 个结果，批次ID %q", len(results), batchId)
+	}
+	for i, result := range results {
+		if result["service_id"] != ids[i] {
+			t.Fatalf("第 %d 个结果为服务 %v，期望 %d", i, result["service_id"], ids[i])
+		}
+		success := ids[i] != 999
+		if result["success"] != success {
+			t.Fatalf("服务 %d 的结果为 %+v", ids[i], result)
+		}
+	}
+	if results[3]["message"] != common.ErrServiceNotFound.Message {
+		t.Fatalf("不存在的服务的结果为 %+v", results[3])
+	}
+
+	// 不同服务的操作同时进行，不超过批量操作的并发数
+	if runtime.maxRunning != batchConcurrency {
+		t.Fatalf("同时执行的命令数最大为 %d，期望 %d", runtime.maxRunning, batchConcurrency)
+	}
+	if executed := runtime.executedCommands(); len(executed) != 8 {
+		t.Fatalf("执行了 %d 个命令，期望 8", len(executed))
+	}
+	for _, id := range ids {
+		if id == 999 {
+			continue
+		}
+		entry := loggedOperations(t, c, id)["start"]
+		if entry.Status != "success" || entry.BatchId != batchId || entry.Trigger != model.TriggerBatch {
+			t.Fatalf("服务 %d 的 start 日志为 %+v，期望批次 %s", id, entry, batchId)
+		}
+	}
+
+	// 同一服务的操作依次执行，命令不会同时执行
+	api := ids[0]
+	service, _ := c.serviceService.GetServiceById(ctx, api)
+	service.CmdRestart = "./api --reload"
+	if err := c.serviceService.UpdateService(ctx, service); err != nil {
+		t.Fatalf("更新服务失败: %v", err)
+	}
+	runtime.script(service.CmdRestart, fakeCommand{output: "reloaded", delay: 50 * time.Millisecond})
+	results, _ = c.BatchOperation(ctx, []int64{api, api, api}, "restart")
+	for _, result := range results {
+		if result["success"] != true {
+			t.Fatalf("重启的结果为 %+v", result)
+		}
+	}
+	if runtime.maxSame != 1 {
+		t.Fatalf("同一服务同时执行的命令数最大为 %d，期望 1", runtime.maxSame)
+	}
+
+	results, _ = c.BatchOperation(ctx, ids[:2], "pause")
+	for _, result := range results {
+		if result["success"] != false || result["message"] != "不支持的操作: pause" {
+			t.Fatalf("不支持的操作的结果为 %+v", result)
+		}
+	}
+}
+
+func TestStopServiceAfterManagedLeaderExited(t *testing.T) {
+	c, runtime := newTestCommandService(t)
+	ctx := context.Background()
+	service := testService("api", 18283)
+	service.RunMode = model.RunModeManaged
+	mustCreate(t, c.serviceService, service)
+
+	// 托管的主进程已退出，派生的子进程仍占用端口
+	exited := &managedProcess{serviceId: service.Id, pid: 900, pgid: 900, exited: true, done: make(chan struct{})}
+	close(exited.done)
+	processManager.Lock()
+	processManager.processes[service.Id] = exited
+	processManager.Unlock()
+	t.Cleanup(func() {
+		processManager.Lock()
+		delete(processManager.processes, service.Id)
+		processManager.Unlock()
+	})
+	child := runtime.listen(service.Port)
+
+	if !c.isServiceRunning(service) {
+		t.Fatalf("主进程退出但端口仍被占用时应视为运行")
+	}
+	if _, err := c.StopService(ctx, service.Id); err != nil {
+		t.Fatalf("停止服务失败: %v", err)
+	}
+	if signals := runtime.sentSignals(); len(signals) != 1 || signals[0].pid != child {
+		t.Fatalf("应按端口终止子进程 %d，实际发送了 %v", child, signals)
+	}
+	if runtime.IsListening(service.Port) {
+		t.Fatalf("停止后端口仍被占用")
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/config"
+	"go_service/app/model"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HealthCheckService 健康检查服务
+// 对运行中的服务周期性执行存活探针，未配置存活探针时请求 HealthCheckUrl，结果保存在内存中供接口和自动重启守护使用
+type HealthCheckService struct {
+	serviceService *ServiceService
+	interval       time.Duration // 服务列表刷新间隔，也是 HealthCheckUrl 的检查间隔
+	timeout        time.Duration // HealthCheckUrl 的请求超时
+	maxConcurrency int
+	stopChannel    chan struct{}
+	wg             sync.WaitGroup
+
+	mutex     sync.Mutex
+	services  []model.ServiceModel
+	lastCheck map[int64]time.Time
+	inFlight  map[int64]bool
+}
+
+// healthCheckTick 调度精度，存活探针的间隔以此为最小单位
+const healthCheckTick = time.Second
+
+func NewHealthCheckService(db *gorm.DB, interval, timeout time.Duration) *HealthCheckService {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &HealthCheckService{
+		serviceService: NewServiceService(db),
+		interval:       interval,
+		timeout:        timeout,
+		maxConcurrency: 10,
+		stopChannel:    make(chan struct{}),
+		lastCheck:      make(map[int64]time.Time),
+		inFlight:       make(map[int64]bool),
+	}
+}
+
+// Start 启动健康检查协程
+func (h *HealthCheckService) Start() {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(healthCheckTick)
+		defer ticker.Stop()
+
+		semaphore := make(chan struct{}, h.maxConcurrency)
+		var lastRefresh time.Time
+		for {
+			if time.Since(lastRefresh) >= h.interval {
+				h.refreshServices()
+				lastRefresh = time.Now()
+			}
+			h.dispatch(semaphore)
+
+			select {
+			case <-ticker.C:
+			case <-h.stopChannel:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止健康检查协程，等待进行中的检查结束
+func (h *HealthCheckService) Stop() {
+	close(h.stopChannel)
+	h.wg.Wait()
+}
+
+// refreshServices 重新加载需要检查的服务
+func (h *HealthCheckService) refreshServices() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	services, err := h.serviceService.GetHealthCheckServices(ctx)
+	if err != nil {
+		log.Printf("健康检查查询服务失败: %v", err)
+		return
+	}
+
+	h.mutex.Lock()
+	h.services = services
+	h.mutex.Unlock()
+}
+
+// dispatch 对到期的服务发起检查
+func (h *HealthCheckService) dispatch(semaphore chan struct{}) {
+	now := time.Now()
+
+	h.mutex.Lock()
+	var due []model.ServiceModel
+	for _, service := range h.services {
+		period := h.interval
+		if service.LivenessProbe != nil {
+			period = probePeriod(service.LivenessProbe)
+		}
+		if h.inFlight[service.Id] || now.Sub(h.lastCheck[service.Id]) < period {
+			continue
+		}
+		h.inFlight[service.Id] = true
+		h.lastCheck[service.Id] = now
+		due = append(due, service)
+	}
+	h.mutex.Unlock()
+
+	for _, service := range due {
+		h.wg.Add(1)
+		go func(service model.ServiceModel) {
+			defer h.wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			h.checkService(service)
+
+			h.mutex.Lock()
+			delete(h.inFlight, service.Id)
+			h.mutex.Unlock()
+		}(service)
+	}
+}
+
+// checkService 检查单个服务，服务未运行时清除结果
+func (h *HealthCheckService) checkService(service model.ServiceModel) {
+	if !isServiceRunning(&service) {
+		clearHealthResult(service.Id)
+		return
+	}
+
+	probe := service.LivenessProbe
+	threshold := 0
+	if probe != nil {
+		threshold = probeFailureThreshold(probe)
+	} else {
+		timeout := int(h.timeout / time.Second)
+		if timeout <= 0 {
+			timeout = 1
+		}
+		probe = &model.ProbeConfig{Type: model.ProbeTypeHTTP, Url: service.HealthCheckUrl, Timeout: timeout}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.stopChannel:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// log 探针匹配托管进程的输出
+	output := func() string { return "" }
+	if process := getManagedProcess(service.Id); process != nil {
+		output = process.output.String
+	}
+
+	probeResult := runProbe(ctx, probe, &service, output)
+	result := &model.ServiceHealthModel{
+		Healthy:          probeResult.Err == nil,
+		StatusCode:       probeResult.StatusCode,
+		Latency:          probeResult.Latency.Milliseconds(),
+		FailureThreshold: threshold,
+		CheckedAt:        time.Now(),
+	}
+	if probeResult.Err != nil {
+		result.Error = probeResult.Err.Error()
+		log.Printf("服务 %s(%d) 健康检查失败: %s", service.Name, service.Id, result.Error)
+	}
+	last := getHealthResult(service.Id)
+	setHealthResult(service.Id, result)
+	h.alertHealth(&service, last, result)
+}
+
+// alertHealth 连续失败次数达到阈值时告警，之后恢复健康时再告警一次
+func (h *HealthCheckService) alertHealth(service *model.ServiceModel, last, result *model.ServiceHealthModel) {
+	threshold := result.FailureThreshold
+	if threshold <= 0 {
+		threshold = config.GlobalConfig.Monitor.UnhealthyThreshold
+	}
+	if threshold <= 0 {
+		threshold = defaultProbeFailureThreshold
+	}
+
+	if !result.Healthy && result.ConsecutiveFailures == threshold {
+		sendAlert(serviceAlert(service, model.AlertHealthFailed, model.AlertLevelWarning,
+			fmt.Sprintf("连续 %d 次健康检查失败: %s", result.ConsecutiveFailures, result.Error)))
+	} else if result.Healthy && last != nil && last.ConsecutiveFailures >= threshold {
+		sendAlert(serviceAlert(service, model.AlertHealthRecovered, model.AlertLevelInfo,
+			fmt.Sprintf("连续 %d 次失败后恢复健康", last.ConsecutiveFailures)))
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/config"
+	"go_service/app/model"
+	"go_service/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 就绪检查参数
+const (
+	readinessPingTimeout = 2 * time.Second
+	logQueueReadyRatio   = 0.9 // 操作日志通道使用率达到该比例时视为未就绪
+)
+
+// startedAt 本进程启动时间
+var startedAt = time.Now()
+
+// HealthService go_service 自身的存活和就绪检查
+type HealthService struct {
+	db             *gorm.DB
+	serviceService *ServiceService
+}
+
+func NewHealthService(db *gorm.DB) *HealthService {
+	return &HealthService{
+		db:             db,
+		serviceService: NewServiceService(db),
+	}
+}
+
+// Liveness 存活检查，不检查任何依赖
+func (h *HealthService) Liveness() *model.LivenessModel {
+	return &model.LivenessModel{
+		Status:    model.HealthStatusUp,
+		Version:   config.GlobalConfig.App.Version,
+		StartedAt: startedAt,
+		Uptime:    int64(time.Since(startedAt) / time.Second),
+	}
+}
+
+// Readiness 就绪检查：数据库连接、操作日志通道积压、端口扫描，withServices 为 true 时附带服务数量统计
+func (h *HealthService) Readiness(ctx context.Context, withServices bool) *model.ReadinessModel {
+	result := &model.ReadinessModel{
+		Status: model.HealthStatusUp,
+		Checks: map[string]*model.DependencyCheckModel{
+			"database":  h.checkDatabase(ctx),
+			"log_queue": h.checkLogQueue(),
+			"port_scan": h.checkPortScan(),
+		},
+		CheckedAt: time.Now(),
+	}
+	for _, check := range result.Checks {
+		if check.Status != model.HealthStatusUp {
+			result.Status = model.HealthStatusDown
+		}
+	}
+
+	// 服务状态依赖数据库和端口扫描，未就绪时不统计
+	if withServices && result.Status == model.HealthStatusUp {
+		result.Services = h.serviceService.HealthCheck(ctx)
+	}
+	return result
+}
+
+// checkDatabase 在超时时间内 ping 数据库，并返回连接池状态
+func (h *HealthService) checkDatabase(ctx context.Context) *model.DependencyCheckModel {
+	start := time.Now()
+	if h.db == nil {
+		return checkFailed(start, fmt.Errorf("数据库未初始化"))
+	}
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return checkFailed(start, err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, readinessPingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(pingCtx); err != nil {
+		return checkFailed(start, err)
+	}
+
+	stats := sqlDB.Stats()
+	return checkPassed(start, map[string]interface{}{
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+		"wait_count":       stats.WaitCount,
+	})
+}
+
+// checkLogQueue 检查操作日志通道的积压，积压过多说明数据库写入跟不上
+func (h *HealthService) checkLogQueue() *model.DependencyCheckModel {
+	start := time.Now()
+
+	logServices.Lock()
+	services := append([]*LogService{}, logServices.services...)
+	logServices.Unlock()
+
+	var usage, capacity int
+	var failed int64
+	for _, service := range services {
+		usage += len(service.logChannel)
+		capacity += cap(service.logChannel)
+		service.stats.RLock()
+		failed += service.stats.failedWrites
+		service.stats.RUnlock()
+	}
+
+	detail := map[string]interface{}{
+		"usage":         usage,
+		"capacity":      capacity,
+		"failed_writes": failed,
+	}
+	if capacity > 0 && float64(usage) >= float64(capacity)*logQueueReadyRatio {
+		check := checkFailed(start, fmt.Errorf("操作日志积压 %d/%d", usage, capacity))
+		check.Detail = detail
+		return check
+	}
+	return checkPassed(start, detail)
+}
+
+// checkPortScan 检查能否获取端口监听列表，服务状态依赖该列表
+func (h *HealthService) checkPortScan() *model.DependencyCheckModel {
+	start := time.Now()
+	portList, err := utils.GetPortList()
+	if err != nil {
+		return checkFailed(start, err)
+	}
+	return checkPassed(start, map[string]interface{}{"listening_ports": len(portList)})
+}
+
+// checkPassed 检查通过
+func checkPassed(start time.Time, detail map[string]interface{}) *model.DependencyCheckModel {
+	return &model.DependencyCheckModel{
+		Status:  model.HealthStatusUp,
+		Latency: time.Since(start).Milliseconds(),
+		Detail:  detail,
+	}
+}
+
+// checkFailed 检查失败
+func checkFailed(start time.Time, err error) *model.DependencyCheckModel {
+	return &model.DependencyCheckModel{
+		Status:  model.HealthStatusDown,
+		Latency: time.Since(start).Milliseconds(),
+		Error:   err.Error(),
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// operations 进行中的服务操作(启动、停止、批量操作、自动重启)，退出时等待完成，
+// 避免服务停在启动或停止的中途，由 beginOperation 登记
+var operations sync.WaitGroup
+
+// WaitOperations 拒绝新的操作并等待进行中的操作完成，超过 ctx 的期限时返回
+func WaitOperations(ctx context.Context) error {
+	stopOperations()
+
+	done := make(chan struct{})
+	go func() {
+		operations.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// logServices 所有日志服务实例，退出时统一写入缓冲的日志
+var logServices = struct {
+	sync.Mutex
+	services []*LogService
+}{}
+
+// registerLogService 登记日志服务实例
+func registerLogService(service *LogService) {
+	logServices.Lock()
+	defer logServices.Unlock()
+	logServices.services = append(logServices.services, service)
+}
+
+// CloseLogServices 关闭所有日志服务，写入通道中缓冲的日志
+func CloseLogServices() {
+	logServices.Lock()
+	services := logServices.services
+	logServices.services = nil
+	logServices.Unlock()
+
+	for _, service := range services {
+		service.Close()
+	}
+}
+
+// CloseOutputLogs 关闭所有服务输出日志文件
+func CloseOutputLogs() {
+	outputLogs.Lock()
+	logs := outputLogs.logs
+	outputLogs.logs = make(map[int64]*outputLog)
+	outputLogs.Unlock()
+
+	for _, current := range logs {
+		if err := current.close(); err != nil {
+			log.Printf("关闭服务输出日志失败: %v", err)
+		}
+	}
+}
+
+// CloseOutputStreams 关闭所有实时输出订阅，使推送中的 SSE 和 WebSocket 连接结束
+func CloseOutputStreams() {
+	outputHub.Lock()
+	var subscriptions []*OutputSubscription
+	for _, subscribers := range outputHub.subscribers {
+		for subscription := range subscribers {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	outputHub.Unlock()
+
+	for _, subscription := range subscriptions {
+		subscription.Close()
+	}
+}
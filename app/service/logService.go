@@ -4,6 +4,7 @@ import (
 	"context"
 	"go_service/app/common"
 	"go_service/app/model"
+	"go_service/app/repository"
 	"log"
 	"sync"
 	"time"
@@ -11,12 +12,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// cleanBatchSize 清理旧日志时每批删除的条数
+const cleanBatchSize = 1000
+
+// 操作日志查询每页默认和最多的条数，导出时每批读取的条数
+const (
+	defaultLogQueryLimit = 50
+	maxLogQueryLimit     = 500
+	exportBatchSize      = 1000
+)
+
 type LogService struct {
-	db          *gorm.DB
+	logs        repository.LogRepository
 	mutex       sync.RWMutex
 	logChannel  chan model.ServiceLog
 	stopChannel chan struct{}
 	wg          sync.WaitGroup
+
+	// 关闭后 LogOperation 直接写入数据库
+	closeMutex sync.RWMutex
+	closed     bool
 	
 	// 性能优化配置
 	batchSize     int
@@ -33,8 +48,13 @@ type LogService struct {
 }
 
 func NewLogService(db *gorm.DB) *LogService {
+	return NewLogServiceWithRepository(repository.NewGormLogRepository(db))
+}
+
+// NewLogServiceWithRepository 使用指定的存储创建日志服务，测试时可使用内存存储
+func NewLogServiceWithRepository(logs repository.LogRepository) *LogService {
 	service := &LogService{
-		db:            db,
+		logs:          logs,
 		batchSize:     50,
 		flushInterval: 5 * time.Second,
 		channelSize:   1000,
@@ -43,8 +63,9 @@ func NewLogService(db *gorm.DB) *LogService {
 	
 	service.logChannel = make(chan model.ServiceLog, service.channelSize)
 	
-	// 启动日志处理协程
+	// 启动日志处理协程，退出时由 CloseLogServices 统一关闭
 	service.startLogProcessor()
+	registerLogService(service)
 	return service
 }
 
@@ -78,7 +99,16 @@ func (l *LogService) startLogProcessor() {
 				}
 				
 			case <-l.stopChannel:
-				// 停止前写入剩余日志
+				// 停止前写入剩余日志，包括通道中尚未处理的
+				for {
+					select {
+					case logEntry := <-l.logChannel:
+						batch = append(batch, logEntry)
+						continue
+					default:
+					}
+					break
+				}
 				if len(batch) > 0 {
 					l.flushLogs(batch)
 				}
@@ -97,7 +127,7 @@ func (l *LogService) flushLogs(logs []model.ServiceLog) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	
-	if err := l.db.WithContext(ctx).CreateInBatches(logs, l.batchSize).Error; err != nil {
+	if err := l.logs.CreateBatch(ctx, logs, l.batchSize); err != nil {
 		log.Printf("批量写入日志失败: %v", err)
 		l.stats.Lock()
 		l.stats.failedWrites++
@@ -112,31 +142,60 @@ func (l *LogService) flushLogs(logs []model.ServiceLog) {
 
 // LogOperation 记录操作日志 - 异步优化版本
 func (l *LogService) LogOperation(ctx context.Context, serviceId int64, operation, status, output, errorMsg string, duration time.Duration) {
-	logEntry := model.ServiceLog{
-		ServiceId: serviceId,
-		Operation: operation,
-		Status:    status,
-		Output:    truncateString(output, 10000), // 限制输出长度
-		Error:     truncateString(errorMsg, 5000), // 限制错误信息长度
-		Duration:  duration.Milliseconds(),
-	}
+	logEntry := newServiceLog(ctx, serviceId, operation, status, output, errorMsg, duration)
 	
+	observeOperation(serviceId, operation, status, duration)
+
+	l.closeMutex.RLock()
+	defer l.closeMutex.RUnlock()
+
+	// 日志服务已关闭，直接写数据库
+	if l.closed {
+		l.writeLog(&logEntry)
+		return
+	}
+
 	// 非阻塞写入通道
 	select {
 	case l.logChannel <- logEntry:
 		// 成功写入通道
 	default:
-		// 通道满了，直接写数据库（降级处理）
+		// 通道满了，直接写数据库（降级处理），关闭时等待写入完成
+		l.wg.Add(1)
 		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			if err := l.db.WithContext(ctx).Create(&logEntry).Error; err != nil {
-				log.Printf("直接写入日志失败: %v", err)
-			}
+			defer l.wg.Done()
+			l.writeLog(&logEntry)
 		}()
 	}
 }
 
+// newServiceLog 创建一条操作日志，操作人、请求ID等来源信息取自上下文
+func newServiceLog(ctx context.Context, serviceId int64, operation, status, output, errorMsg string, duration time.Duration) model.ServiceLog {
+	source := operationSource(ctx)
+	return model.ServiceLog{
+		ServiceId: serviceId,
+		Operation: operation,
+		Status:    status,
+		Output:    truncateString(output, 10000), // 限制输出长度
+		Error:     truncateString(errorMsg, 5000), // 限制错误信息长度
+		Duration:  duration.Milliseconds(),
+		Actor:     source.Actor,
+		ClientIp:  source.ClientIp,
+		RequestId: source.RequestId,
+		Trigger:   source.Trigger,
+		BatchId:   source.BatchId,
+	}
+}
+
+// writeLog 直接写入一条日志
+func (l *LogService) writeLog(logEntry *model.ServiceLog) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.logs.Create(ctx, logEntry); err != nil {
+		log.Printf("直接写入日志失败: %v", err)
+	}
+}
+
 // truncateString 截断字符串
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -150,15 +209,9 @@ func (l *LogService) RecordServiceOperation(ctx context.Context, serviceId int64
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
-	log := &model.ServiceLog{
-		ServiceId: serviceId,
-		Operation: operation,
-		Status:    status,
-		Output:    output,
-		Error:     errorMsg,
-	}
+	log := newServiceLog(ctx, serviceId, operation, status, output, errorMsg, 0)
 
-	if err := l.db.WithContext(ctx).Create(log).Error; err != nil {
+	if err := l.logs.Create(ctx, &log); err != nil {
 		return common.WrapError(common.ErrCodeDatabaseError, "记录操作日志失败", err)
 	}
 
@@ -170,68 +223,120 @@ func (l *LogService) GetServiceLogs(ctx context.Context, serviceId int64, limit
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
 
-	var logs []model.ServiceLog
-	query := l.db.WithContext(ctx).Where("service_id = ?", serviceId).Order("created_at DESC")
-	
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
-
-	if err := query.Find(&logs).Error; err != nil {
+	logs, err := l.logs.ListByService(ctx, serviceId, limit)
+	if err != nil {
 		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询操作日志失败", err)
 	}
 
 	return logs, nil
 }
 
-// GetAllLogs 获取所有服务操作日志
-func (l *LogService) GetAllLogs(ctx context.Context, page, pageSize int) (*model.LogListResponse, error) {
+// QueryLogs 按条件查询操作日志，按 id 倒序，使用游标分页
+func (l *LogService) QueryLogs(ctx context.Context, req *model.LogListRequest) (*model.LogListResponse, error) {
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 20
+	if req.Limit <= 0 {
+		req.Limit = defaultLogQueryLimit
+	} else if req.Limit > maxLogQueryLimit {
+		req.Limit = maxLogQueryLimit
 	}
 
-	var total int64
-	if err := l.db.WithContext(ctx).Model(&model.ServiceLog{}).Count(&total).Error; err != nil {
-		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询日志总数失败", err)
+	// 多查一条判断是否还有下一页
+	logs, err := l.logs.Query(ctx, req, req.Cursor, req.Limit+1)
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询操作日志失败", err)
 	}
 
-	var logs []model.ServiceLog
-	offset := (page - 1) * pageSize
-	if err := l.db.WithContext(ctx).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&logs).Error; err != nil {
-		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询操作日志失败", err)
+	response := &model.LogListResponse{List: logs}
+	if len(logs) > req.Limit {
+		response.List = logs[:req.Limit]
+		response.HasMore = true
+	}
+	if len(response.List) > 0 {
+		response.NextCursor = response.List[len(response.List)-1].Id
 	}
+	return response, nil
+}
 
-	return &model.LogListResponse{
-		List:  logs,
-		Total: total,
-		Page:  page,
-		Size:  pageSize,
-	}, nil
+// ExportLogs 按条件分批读取所有匹配的操作日志，每批调用一次 write，write 返回错误时停止
+func (l *LogService) ExportLogs(ctx context.Context, req *model.LogListRequest, write func([]model.ServiceLog) error) error {
+	cursor := req.Cursor
+	for {
+		l.mutex.RLock()
+		batch, err := l.logs.Query(ctx, req, cursor, exportBatchSize)
+		l.mutex.RUnlock()
+		if err != nil {
+			return common.WrapError(common.ErrCodeDatabaseError, "查询操作日志失败", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := write(batch); err != nil {
+			return err
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		cursor = batch[len(batch)-1].Id
+	}
 }
 
-// CleanOldLogs 清理旧日志
-func (l *LogService) CleanOldLogs(ctx context.Context, days int) error {
+// CleanOldLogs 删除服务在 cutoff 之前的日志，至少保留最近 keepLast 条
+// archive 不为空时每批日志先归档再删除，归档失败时停止删除，返回已删除的条数
+func (l *LogService) CleanOldLogs(ctx context.Context, serviceId int64, cutoff time.Time, keepLast int, archive func([]model.ServiceLog) error) (int64, error) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
-	if days <= 0 {
-		days = 30 // 默认保留30天
+	// 最近 keepLast 条中最早的一条之前的日志才能删除
+	var keepFrom int64
+	if keepLast > 0 {
+		id, err := l.logs.NthLatestId(ctx, serviceId, keepLast)
+		if err != nil {
+			return 0, common.WrapError(common.ErrCodeDatabaseError, "查询操作日志失败", err)
+		}
+		if id == 0 {
+			return 0, nil
+		}
+		keepFrom = id
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -days)
-	
-	result := l.db.WithContext(ctx).Where("created_at < ?", cutoffTime).Delete(&model.ServiceLog{})
-	if result.Error != nil {
-		return common.WrapError(common.ErrCodeDatabaseError, "清理旧日志失败", result.Error)
-	}
+	var deleted int64
+	for {
+		var ids []int64
+		if archive != nil {
+			batch, err := l.logs.ListExpired(ctx, serviceId, cutoff, keepFrom, cleanBatchSize)
+			if err != nil {
+				return deleted, common.WrapError(common.ErrCodeDatabaseError, "查询过期日志失败", err)
+			}
+			if len(batch) == 0 {
+				return deleted, nil
+			}
+			if err := archive(batch); err != nil {
+				return deleted, common.WrapError(common.ErrCodeFileError, "归档日志失败", err)
+			}
+			for _, entry := range batch {
+				ids = append(ids, entry.Id)
+			}
+		} else {
+			var err error
+			if ids, err = l.logs.ListExpiredIds(ctx, serviceId, cutoff, keepFrom, cleanBatchSize); err != nil {
+				return deleted, common.WrapError(common.ErrCodeDatabaseError, "查询过期日志失败", err)
+			}
+			if len(ids) == 0 {
+				return deleted, nil
+			}
+		}
 
-	return nil
+		count, err := l.logs.DeleteByIds(ctx, ids)
+		if err != nil {
+			return deleted, common.WrapError(common.ErrCodeDatabaseError, "清理旧日志失败", err)
+		}
+		deleted += count
+		if len(ids) < cleanBatchSize {
+			return deleted, nil
+		}
+	}
 }
 
 // GetLogStats 获取日志统计信息
@@ -239,42 +344,36 @@ func (l *LogService) GetLogStats(ctx context.Context) (map[string]interface{}, e
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
 
-	stats := make(map[string]interface{})
-
-	// 总日志数
-	var totalLogs int64
-	l.db.WithContext(ctx).Model(&model.ServiceLog{}).Count(&totalLogs)
-	stats["total_logs"] = totalLogs
-
-	// 今日日志数
-	today := time.Now().Format("2006-01-02")
-	var todayLogs int64
-	l.db.WithContext(ctx).Model(&model.ServiceLog{}).Where("DATE(created_at) = ?", today).Count(&todayLogs)
-	stats["today_logs"] = todayLogs
-
-	// 成功/失败统计
-	var successLogs, failedLogs int64
-	l.db.WithContext(ctx).Model(&model.ServiceLog{}).Where("status = ?", "success").Count(&successLogs)
-	l.db.WithContext(ctx).Model(&model.ServiceLog{}).Where("status = ?", "failed").Count(&failedLogs)
-	stats["success_logs"] = successLogs
-	stats["failed_logs"] = failedLogs
-
-	// 操作类型统计
-	var operationStats []map[string]interface{}
-	l.db.WithContext(ctx).Model(&model.ServiceLog{}).
-		Select("operation, COUNT(*) as count").
-		Group("operation").
-		Scan(&operationStats)
-	stats["operation_stats"] = operationStats
+	// 今日日志数按当天的时间范围统计
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	result, err := l.logs.Stats(ctx, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询日志统计失败", err)
+	}
 
+	stats := map[string]interface{}{
+		"total_logs":      result.Total,
+		"today_logs":      result.RangeCount,
+		"success_logs":    result.Success,
+		"failed_logs":     result.Failed,
+		"operation_stats": result.Operations,
+	}
 	return stats, nil
 }
 
-// Close 关闭日志服务
+// Close 关闭日志服务，写入缓冲的日志后返回，可重复调用
 func (l *LogService) Close() {
+	l.closeMutex.Lock()
+	if l.closed {
+		l.closeMutex.Unlock()
+		return
+	}
+	l.closed = true
+	l.closeMutex.Unlock()
+
 	close(l.stopChannel)
 	l.wg.Wait()
-	close(l.logChannel)
 }
 
 // GetServiceStats 获取日志服务统计信息
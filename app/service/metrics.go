@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/procfs"
+	"gorm.io/gorm"
+)
+
+// metricsNamespace 指标名前缀
+const metricsNamespace = "go_service"
+
+// 服务操作指标，与 service_log 中的记录一一对应，由 LogService.LogOperation 更新
+var (
+	operationTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "operations_total",
+		Help:      "服务操作次数，按操作类型和结果划分",
+	}, []string{"operation", "status"})
+
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "operation_duration_seconds",
+		Help:      "服务操作耗时",
+		Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+	}, []string{"operation", "status"})
+)
+
+// serviceRestarts 各服务自本进程启动以来的重启次数(手动重启、强制重启和自动重启)
+var serviceRestarts = struct {
+	sync.Mutex
+	counts map[int64]int
+}{
+	counts: make(map[int64]int),
+}
+
+// observeOperation 记录一次服务操作
+func observeOperation(serviceId int64, operation, status string, duration time.Duration) {
+	operationTotal.WithLabelValues(operation, status).Inc()
+	operationDuration.WithLabelValues(operation, status).Observe(duration.Seconds())
+
+	if status != "success" {
+		return
+	}
+	switch operation {
+	case "restart", "force_restart", "auto_restart":
+		serviceRestarts.Lock()
+		serviceRestarts.counts[serviceId]++
+		serviceRestarts.Unlock()
+	}
+}
+
+// metricsOnce 指标只注册一次
+var metricsOnce sync.Once
+
+// RegisterMetrics 注册服务操作、服务状态和日志服务的指标，服务状态在采集时查询
+func RegisterMetrics(db *gorm.DB) {
+	metricsOnce.Do(func() {
+		prometheus.MustRegister(operationTotal, operationDuration, newServiceCollector(db), newLogServiceCollector())
+	})
+}
+
+// serviceLabels 服务指标的标签
+var serviceLabels = []string{"service_id", "service", "group"}
+
+// serviceCollector 采集各服务的运行状态、健康状态、重启次数和进程资源
+type serviceCollector struct {
+	serviceService *ServiceService
+
+	up        *prometheus.Desc
+	healthy   *prometheus.Desc
+	restarts  *prometheus.Desc
+	cpu       *prometheus.Desc
+	rss       *prometheus.Desc
+	openFds   *prometheus.Desc
+	scrapeErr *prometheus.Desc
+}
+
+func newServiceCollector(db *gorm.DB) *serviceCollector {
+	name := func(name string) string {
+		return prometheus.BuildFQName(metricsNamespace, "service", name)
+	}
+	return &serviceCollector{
+		serviceService: NewServiceService(db),
+		up:             prometheus.NewDesc(name("up"), "服务是否运行，1 运行，0 停止", serviceLabels, nil),
+		healthy:        prometheus.NewDesc(name("healthy"), "最近一次健康检查是否通过，1 通过，0 失败，未检查时不输出", serviceLabels, nil),
+		restarts:       prometheus.NewDesc(name("restarts_total"), "服务重启次数，包括手动重启、强制重启和自动重启", serviceLabels, nil),
+		cpu:            prometheus.NewDesc(name("cpu_seconds_total"), "服务进程的CPU时间(用户态和内核态)", serviceLabels, nil),
+		rss:            prometheus.NewDesc(name("resident_memory_bytes"), "服务进程的常驻内存", serviceLabels, nil),
+		openFds:        prometheus.NewDesc(name("open_fds"), "服务进程打开的文件描述符数量", serviceLabels, nil),
+		scrapeErr:      prometheus.NewDesc(name("scrape_error"), "采集服务状态是否失败，1 失败", nil, nil),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (s *serviceCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{s.up, s.healthy, s.restarts, s.cpu, s.rss, s.openFds, s.scrapeErr} {
+		ch <- desc
+	}
+}
+
+// Collect 实现 prometheus.Collector，进程资源从 /proc 读取，读取失败(如非 Linux 或进程已退出)时不输出
+func (s *serviceCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	services, err := s.serviceService.GetAllServicesWithStatus(ctx)
+	if err != nil {
+		log.Printf("采集服务指标失败: %v", err)
+		ch <- prometheus.MustNewConstMetric(s.scrapeErr, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(s.scrapeErr, prometheus.GaugeValue, 0)
+
+	serviceRestarts.Lock()
+	restarts := make(map[int64]int, len(serviceRestarts.counts))
+	for id, count := range serviceRestarts.counts {
+		restarts[id] = count
+	}
+	serviceRestarts.Unlock()
+
+	for _, service := range services {
+		labels := []string{strconv.FormatInt(service.Id, 10), service.Name, service.Group}
+
+		up := 0.0
+		if service.Status != 0 {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(s.up, prometheus.GaugeValue, up, labels...)
+		ch <- prometheus.MustNewConstMetric(s.restarts, prometheus.CounterValue, float64(restarts[service.Id]), labels...)
+
+		if service.Health != nil {
+			healthy := 0.0
+			if service.Health.Healthy {
+				healthy = 1
+			}
+			ch <- prometheus.MustNewConstMetric(s.healthy, prometheus.GaugeValue, healthy, labels...)
+		}
+
+		pid, err := strconv.Atoi(service.Pid)
+		if up == 0 || err != nil || pid <= 0 {
+			continue
+		}
+		proc, err := procfs.NewProc(pid)
+		if err != nil {
+			continue
+		}
+		if stat, err := proc.Stat(); err == nil {
+			ch <- prometheus.MustNewConstMetric(s.cpu, prometheus.CounterValue, stat.CPUTime(), labels...)
+			ch <- prometheus.MustNewConstMetric(s.rss, prometheus.GaugeValue, float64(stat.ResidentMemory()), labels...)
+		}
+		if fds, err := proc.FileDescriptorsLen(); err == nil {
+			ch <- prometheus.MustNewConstMetric(s.openFds, prometheus.GaugeValue, float64(fds), labels...)
+		}
+	}
+}
+
+// logServiceCollector 采集日志服务的写入统计和通道使用情况，多个实例的数据合并输出
+type logServiceCollector struct {
+	channelUsage    *prometheus.Desc
+	channelCapacity *prometheus.Desc
+	written         *prometheus.Desc
+	failedWrites    *prometheus.Desc
+	lastFlush       *prometheus.Desc
+}
+
+func newLogServiceCollector() *logServiceCollector {
+	name := func(name string) string {
+		return prometheus.BuildFQName(metricsNamespace, "log_service", name)
+	}
+	return &logServiceCollector{
+		channelUsage:    prometheus.NewDesc(name("channel_usage"), "日志通道中等待写入的日志数", nil, nil),
+		channelCapacity: prometheus.NewDesc(name("channel_capacity"), "日志通道容量", nil, nil),
+		written:         prometheus.NewDesc(name("written_total"), "已写入数据库的日志数", nil, nil),
+		failedWrites:    prometheus.NewDesc(name("failed_writes_total"), "批量写入失败次数", nil, nil),
+		lastFlush:       prometheus.NewDesc(name("last_flush_timestamp_seconds"), "最近一次批量写入的时间", nil, nil),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (l *logServiceCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{l.channelUsage, l.channelCapacity, l.written, l.failedWrites, l.lastFlush} {
+		ch <- desc
+	}
+}
+
+// Collect 实现 prometheus.Collector
+func (l *logServiceCollector) Collect(ch chan<- prometheus.Metric) {
+	logServices.Lock()
+	services := append([]*LogService{}, logServices.services...)
+	logServices.Unlock()
+
+	var usage, capacity int
+	var written, failed int64
+	var lastFlush time.Time
+	for _, service := range services {
+		service.stats.RLock()
+		written += service.stats.totalLogs
+		failed += service.stats.failedWrites
+		if service.stats.lastFlush.After(lastFlush) {
+			lastFlush = service.stats.lastFlush
+		}
+		service.stats.RUnlock()
+		usage += len(service.logChannel)
+		capacity += cap(service.logChannel)
+	}
+
+	ch <- prometheus.MustNewConstMetric(l.channelUsage, prometheus.GaugeValue, float64(usage))
+	ch <- prometheus.MustNewConstMetric(l.channelCapacity, prometheus.GaugeValue, float64(capacity))
+	ch <- prometheus.MustNewConstMetric(l.written, prometheus.CounterValue, float64(written))
+	ch <- prometheus.MustNewConstMetric(l.failedWrites, prometheus.CounterValue, float64(failed))
+	if !lastFlush.IsZero() {
+		ch <- prometheus.MustNewConstMetric(l.lastFlush, prometheus.GaugeValue, float64(lastFlush.Unix()))
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"go_service/app/common"
+	"go_service/app/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService 通知渠道、通知规则和静默窗口的管理
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{
+		db: db,
+	}
+}
+
+// ListChannels 获取所有通知渠道
+func (n *NotificationService) ListChannels(ctx context.Context) ([]model.NotificationChannelModel, error) {
+	channels := []model.NotificationChannelModel{}
+	if err := n.db.WithContext(ctx).Order("id").Find(&channels).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询通知渠道失败", err)
+	}
+	return channels, nil
+}
+
+// SaveChannel 添加或修改通知渠道，Id 为 0 时添加
+func (n *NotificationService) SaveChannel(ctx context.Context, channel *model.NotificationChannelModel) error {
+	if err := channel.Validate(); err != nil {
+		return common.WrapError(common.ErrCodeInvalidParam, "通知渠道验证失败", err)
+	}
+
+	var count int64
+	if err := n.db.WithContext(ctx).Model(&model.NotificationChannelModel{}).
+		Where("name = ? AND id != ?", channel.Name, channel.Id).Count(&count).Error; err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "查询通知渠道失败", err)
+	}
+	if count > 0 {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, "渠道名称已存在")
+	}
+
+	if channel.Id == 0 {
+		if err := n.db.WithContext(ctx).Create(channel).Error; err != nil {
+			return common.WrapError(common.ErrCodeDatabaseError, "添加通知渠道失败", err)
+		}
+		return nil
+	}
+	return n.update(ctx, &model.NotificationChannelModel{}, channel.Id, channel, "通知渠道")
+}
+
+// DeleteChannel 删除通知渠道，仍被规则使用时不能删除
+func (n *NotificationService) DeleteChannel(ctx context.Context, id int64) error {
+	rules, err := n.ListRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		for _, channelId := range append(append([]int64{}, rule.ChannelIds...), rule.EscalateChannelIds...) {
+			if channelId == id {
+				return common.NewBusinessError(common.ErrCodeInvalidParam, "通知渠道正在被规则 "+rule.Name+" 使用")
+			}
+		}
+	}
+	return n.delete(ctx, &model.NotificationChannelModel{}, id, "通知渠道")
+}
+
+// ListRules 获取所有通知规则
+func (n *NotificationService) ListRules(ctx context.Context) ([]model.NotificationRuleModel, error) {
+	rules := []model.NotificationRuleModel{}
+	if err := n.db.WithContext(ctx).Order("id").Find(&rules).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询通知规则失败", err)
+	}
+	return rules, nil
+}
+
+// SaveRule 添加或修改通知规则，Id 为 0 时添加
+func (n *NotificationService) SaveRule(ctx context.Context, rule *model.NotificationRuleModel) error {
+	if err := rule.Validate(); err != nil {
+		return common.WrapError(common.ErrCodeInvalidParam, "通知规则验证失败", err)
+	}
+
+	// 检查渠道是否存在
+	channelIds := append(append([]int64{}, rule.ChannelIds...), rule.EscalateChannelIds...)
+	var channels []model.NotificationChannelModel
+	if err := n.db.WithContext(ctx).Where("id IN ?", channelIds).Find(&channels).Error; err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "查询通知渠道失败", err)
+	}
+	existing := make(map[int64]bool, len(channels))
+	for _, channel := range channels {
+		existing[channel.Id] = true
+	}
+	for _, id := range channelIds {
+		if !existing[id] {
+			return common.NewBusinessError(common.ErrCodeInvalidParam, "通知渠道不存在")
+		}
+	}
+
+	if rule.Id == 0 {
+		if err := n.db.WithContext(ctx).Create(rule).Error; err != nil {
+			return common.WrapError(common.ErrCodeDatabaseError, "添加通知规则失败", err)
+		}
+		return nil
+	}
+	return n.update(ctx, &model.NotificationRuleModel{}, rule.Id, rule, "通知规则")
+}
+
+// DeleteRule 删除通知规则
+func (n *NotificationService) DeleteRule(ctx context.Context, id int64) error {
+	return n.delete(ctx, &model.NotificationRuleModel{}, id, "通知规则")
+}
+
+// ListSilences 获取静默窗口，activeOnly 为 true 时只返回未结束的
+func (n *NotificationService) ListSilences(ctx context.Context, activeOnly bool) ([]model.NotificationSilenceModel, error) {
+	silences := []model.NotificationSilenceModel{}
+	query := n.db.WithContext(ctx).Order("id DESC")
+	if activeOnly {
+		query = query.Where("ends_at > ?", time.Now())
+	}
+	if err := query.Find(&silences).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询静默窗口失败", err)
+	}
+	return silences, nil
+}
+
+// CreateSilence 添加静默窗口
+func (n *NotificationService) CreateSilence(ctx context.Context, silence *model.NotificationSilenceModel) error {
+	if err := silence.Validate(); err != nil {
+		return common.WrapError(common.ErrCodeInvalidParam, "静默窗口验证失败", err)
+	}
+	if err := n.db.WithContext(ctx).Create(silence).Error; err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "添加静默窗口失败", err)
+	}
+	return nil
+}
+
+// ExpireSilence 立即结束静默窗口，保留记录
+func (n *NotificationService) ExpireSilence(ctx context.Context, id int64) error {
+	result := n.db.WithContext(ctx).Model(&model.NotificationSilenceModel{}).
+		Where("id = ? AND ends_at > ?", id, time.Now()).Update("ends_at", time.Now())
+	if result.Error != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "结束静默窗口失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, "静默窗口不存在或已结束")
+	}
+	return nil
+}
+
+// loadRouting 加载启用的规则、渠道和生效中的静默窗口，供告警发送使用
+func (n *NotificationService) loadRouting(ctx context.Context) ([]model.NotificationRuleModel, map[int64]model.NotificationChannelModel, []model.NotificationSilenceModel, error) {
+	var rules []model.NotificationRuleModel
+	if err := n.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&rules).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	var channelList []model.NotificationChannelModel
+	if err := n.db.WithContext(ctx).Where("enabled = ?", true).Find(&channelList).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	channels := make(map[int64]model.NotificationChannelModel, len(channelList))
+	for _, channel := range channelList {
+		channels[channel.Id] = channel
+	}
+
+	var silences []model.NotificationSilenceModel
+	now := time.Now()
+	if err := n.db.WithContext(ctx).Where("starts_at <= ? AND ends_at > ?", now, now).Find(&silences).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	return rules, channels, silences, nil
+}
+
+// update 按 Id 修改所有字段，记录不存在时返回错误
+func (n *NotificationService) update(ctx context.Context, table interface{}, id int64, value interface{}, name string) error {
+	result := n.db.WithContext(ctx).Model(table).Where("id = ?", id).Select("*").Omit("id", "created_at").Updates(value)
+	if result.Error != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "修改"+name+"失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, name+"不存在")
+	}
+	return nil
+}
+
+// delete 按 Id 删除，记录不存在时返回错误
+func (n *NotificationService) delete(ctx context.Context, table interface{}, id int64, name string) error {
+	result := n.db.WithContext(ctx).Delete(table, id)
+	if result.Error != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "删除"+name+"失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, name+"不存在")
+	}
+	return nil
+}
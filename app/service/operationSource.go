@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go_service/app/model"
+	"strconv"
+	"time"
+)
+
+// operationSourceKey 上下文中保存操作来源
+type operationSourceKey struct{}
+
+// WithOperationSource 返回带操作来源的上下文，记录操作日志时写入操作人、来源IP、请求ID等
+func WithOperationSource(ctx context.Context, source model.OperationSource) context.Context {
+	return context.WithValue(ctx, operationSourceKey{}, source)
+}
+
+// operationSource 上下文中的操作来源，未设置时为 go_service 自身触发
+func operationSource(ctx context.Context) model.OperationSource {
+	if source, ok := ctx.Value(operationSourceKey{}).(model.OperationSource); ok {
+		if source.Trigger == "" {
+			source.Trigger = model.TriggerApi
+		}
+		return source
+	}
+	return model.OperationSource{Trigger: model.TriggerSystem}
+}
+
+// withTrigger 返回指定触发方式的上下文，保留操作人等其他来源信息
+func withTrigger(ctx context.Context, trigger string) context.Context {
+	source := operationSource(ctx)
+	source.Trigger = trigger
+	return WithOperationSource(ctx, source)
+}
+
+// withBatch 返回批量操作的上下文，同一批操作的日志使用相同的批次ID
+func withBatch(ctx context.Context) (context.Context, string) {
+	source := operationSource(ctx)
+	source.Trigger = model.TriggerBatch
+	source.BatchId = NewTraceId()
+	return WithOperationSource(ctx, source), source.BatchId
+}
+
+// NewTraceId 生成请求ID或批次ID，32位十六进制字符串
+func NewTraceId() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
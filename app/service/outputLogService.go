@@ -0,0 +1,510 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/config"
+	"go_service/app/model"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+	"gorm.io/gorm"
+)
+
+// 服务输出的来源
+const (
+	OutputStreamStdout  = "stdout"
+	OutputStreamStderr  = "stderr"
+	OutputStreamCommand = "command" // 启动、停止、重启命令的输出
+	OutputStreamSystem  = "system"  // go_service 记录的进程事件
+)
+
+// 输出日志参数
+const (
+	outputTimeLayout    = "2006-01-02 15:04:05.000"
+	outputMaxLineSize   = 64 * 1024   // 单行最大长度，超过后直接落盘
+	outputMaxRangeSize  = 1024 * 1024 // 按字节读取的最大长度
+	outputMaxTailLines  = 5000        // 尾部读取的最大行数
+	outputMaxWindowRows = 10000       // 按时间读取的最大行数
+	outputReadChunkSize = 32 * 1024   // 倒序读取文件的块大小
+	outputScanBufSize   = 1024 * 1024 // 按行扫描的缓冲区大小
+)
+
+// outputLog 单个服务的输出日志文件，按大小由 lumberjack 滚动，跨天时主动滚动
+type outputLog struct {
+	serviceId int64
+	mutex     sync.Mutex
+	logger    *lumberjack.Logger
+	day       string // 当前文件所属日期
+}
+
+// outputLogs 服务输出日志表，按服务ID索引
+var outputLogs = struct {
+	sync.Mutex
+	logs map[int64]*outputLog
+}{
+	logs: make(map[int64]*outputLog),
+}
+
+// outputLogDir 服务输出日志目录
+func outputLogDir() string {
+	if config.GlobalConfig != nil && config.GlobalConfig.Log.ServiceDir != "" {
+		return config.GlobalConfig.Log.ServiceDir
+	}
+	return "logs/services"
+}
+
+// outputLogName 服务输出日志文件名(不含扩展名)
+func outputLogName(serviceId int64) string {
+	return fmt.Sprintf("service_%d", serviceId)
+}
+
+// outputLogPath 服务当前输出日志文件路径
+func outputLogPath(serviceId int64) string {
+	return filepath.Join(outputLogDir(), outputLogName(serviceId)+".log")
+}
+
+// getOutputLog 获取服务的输出日志，不存在时按当前配置创建
+func getOutputLog(serviceId int64) *outputLog {
+	outputLogs.Lock()
+	defer outputLogs.Unlock()
+
+	if current, ok := outputLogs.logs[serviceId]; ok {
+		return current
+	}
+
+	path := outputLogPath(serviceId)
+	logger := &lumberjack.Logger{Filename: path, LocalTime: true}
+	if config.GlobalConfig != nil {
+		logConfig := config.GlobalConfig.Log
+		logger.MaxSize = logConfig.MaxSize
+		logger.MaxBackups = logConfig.MaxBackups
+		logger.MaxAge = logConfig.MaxAge
+		logger.Compress = logConfig.Compress
+	}
+
+	// 沿用已有文件时以其修改日期为准，跨天后第一次写入即滚动
+	day := time.Now().Format("2006-01-02")
+	if info, err := os.Stat(path); err == nil {
+		day = info.ModTime().Format("2006-01-02")
+	}
+
+	current := &outputLog{serviceId: serviceId, logger: logger, day: day}
+	outputLogs.logs[serviceId] = current
+	return current
+}
+
+// writeLine 写入一行带时间和来源的输出，并推送给实时输出的订阅者
+func (o *outputLog) writeLine(stream string, line []byte) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now := time.Now()
+	if day := now.Format("2006-01-02"); day != o.day {
+		if err := o.logger.Rotate(); err != nil {
+			log.Printf("滚动服务输出日志失败: %v", err)
+		}
+		o.day = day
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(now.Format(outputTimeLayout))
+	buf.WriteString(" [")
+	buf.WriteString(stream)
+	buf.WriteString("] ")
+	text := bytes.TrimRight(line, "\r\n")
+	buf.Write(text)
+	buf.WriteByte('\n')
+	if _, err := o.logger.Write(buf.Bytes()); err != nil {
+		log.Printf("写入服务输出日志失败: %v", err)
+	}
+
+	publishOutput(o.serviceId, model.OutputLineModel{Time: now, Stream: stream, Text: string(text)})
+}
+
+// close 关闭日志文件
+func (o *outputLog) close() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.logger.Close()
+}
+
+// outputStreamWriter 将输出按行写入服务输出日志，不完整的行暂存到下一次写入
+type outputStreamWriter struct {
+	log     *outputLog
+	stream  string
+	mutex   sync.Mutex
+	partial []byte
+}
+
+// newOutputStreamWriter 创建服务指定来源的输出写入器
+func newOutputStreamWriter(serviceId int64, stream string) *outputStreamWriter {
+	return &outputStreamWriter{log: getOutputLog(serviceId), stream: stream}
+}
+
+func (w *outputStreamWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	data := append(w.partial, p...)
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			break
+		}
+		w.log.writeLine(w.stream, data[:index])
+		data = data[index+1:]
+	}
+	if len(data) > outputMaxLineSize {
+		w.log.writeLine(w.stream, data)
+		data = nil
+	}
+	w.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Flush 写入暂存的不完整行
+func (w *outputStreamWriter) Flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.partial) > 0 {
+		w.log.writeLine(w.stream, w.partial)
+		w.partial = nil
+	}
+}
+
+// recordOutput 将一段输出逐行写入服务输出日志
+func recordOutput(serviceId int64, stream, output string) {
+	if output == "" {
+		return
+	}
+	writer := newOutputStreamWriter(serviceId, stream)
+	writer.Write([]byte(output))
+	writer.Flush()
+}
+
+// recordOutputEvent 记录 go_service 产生的进程事件
+func recordOutputEvent(serviceId int64, format string, args ...interface{}) {
+	getOutputLog(serviceId).writeLine(OutputStreamSystem, []byte(fmt.Sprintf(format, args...)))
+}
+
+// closeOutputLog 关闭服务的输出日志文件
+func closeOutputLog(serviceId int64) {
+	outputLogs.Lock()
+	current, ok := outputLogs.logs[serviceId]
+	delete(outputLogs.logs, serviceId)
+	outputLogs.Unlock()
+
+	if ok {
+		if err := current.close(); err != nil {
+			log.Printf("关闭服务输出日志失败: %v", err)
+		}
+	}
+}
+
+// OutputLogService 服务输出日志查询
+type OutputLogService struct {
+	serviceService *ServiceService
+}
+
+func NewOutputLogService(db *gorm.DB) *OutputLogService {
+	return &OutputLogService{
+		serviceService: NewServiceService(db),
+	}
+}
+
+// Tail 读取服务最后 lines 行输出，当前文件不足时继续读取滚动后的文件
+func (s *OutputLogService) Tail(ctx context.Context, serviceId int64, lines int) (*model.OutputTailModel, error) {
+	if _, err := s.serviceService.GetServiceById(ctx, serviceId); err != nil {
+		return nil, err
+	}
+	if lines <= 0 {
+		lines = 100
+	}
+	if lines > outputMaxTailLines {
+		lines = outputMaxTailLines
+	}
+
+	files, err := outputLogFiles(serviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for i := len(files) - 1; i >= 0 && len(result) < lines; i-- {
+		fileLines, err := readLastLines(files[i], lines-len(result))
+		if err != nil {
+			return nil, common.WrapError(common.ErrCodeFileError, "读取服务输出失败", err)
+		}
+		result = append(fileLines, result...)
+	}
+
+	return &model.OutputTailModel{
+		ServiceId: serviceId,
+		File:      outputLogPath(serviceId),
+		Lines:     parseOutputLines(result),
+	}, nil
+}
+
+// ReadRange 按字节读取服务当前输出文件，length 超出上限时截断
+func (s *OutputLogService) ReadRange(ctx context.Context, serviceId, offset, length int64) (*model.OutputRangeModel, error) {
+	if _, err := s.serviceService.GetServiceById(ctx, serviceId); err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "offset不能小于0")
+	}
+	if length <= 0 || length > outputMaxRangeSize {
+		length = outputMaxRangeSize
+	}
+
+	path := outputLogPath(serviceId)
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &model.OutputRangeModel{ServiceId: serviceId, File: path, Offset: offset}, nil
+		}
+		return nil, common.WrapError(common.ErrCodeFileError, "读取服务输出失败", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeFileError, "读取服务输出失败", err)
+	}
+
+	result := &model.OutputRangeModel{ServiceId: serviceId, File: path, Offset: offset, Size: info.Size()}
+	if offset >= info.Size() {
+		result.NextOffset = offset
+		return result, nil
+	}
+
+	data := make([]byte, length)
+	n, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, common.WrapError(common.ErrCodeFileError, "读取服务输出失败", err)
+	}
+	result.Length = int64(n)
+	result.NextOffset = offset + int64(n)
+	result.Content = string(data[:n])
+	return result, nil
+}
+
+// ReadWindow 读取时间范围内的服务输出，按时间正序返回，最多 limit 行
+func (s *OutputLogService) ReadWindow(ctx context.Context, serviceId int64, since, until time.Time, limit int) (*model.OutputTailModel, error) {
+	if _, err := s.serviceService.GetServiceById(ctx, serviceId); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > outputMaxWindowRows {
+		limit = outputMaxWindowRows
+	}
+	if until.IsZero() {
+		until = time.Now()
+	}
+	if !since.IsZero() && since.After(until) {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "开始时间不能晚于结束时间")
+	}
+
+	files, err := outputLogFiles(serviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.OutputTailModel{
+		ServiceId: serviceId,
+		File:      outputLogPath(serviceId),
+		Lines:     []model.OutputLineModel{},
+	}
+	for _, path := range files {
+		// 修改时间早于开始时间的文件不包含范围内的输出
+		if info, err := os.Stat(path); err == nil && !since.IsZero() && info.ModTime().Before(since) {
+			continue
+		}
+
+		done, err := scanOutputFile(path, func(line model.OutputLineModel) bool {
+			if line.Time.IsZero() || line.Time.Before(since) {
+				return true
+			}
+			if line.Time.After(until) {
+				return false
+			}
+			result.Lines = append(result.Lines, line)
+			if len(result.Lines) >= limit {
+				result.Truncated = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, common.WrapError(common.ErrCodeFileError, "读取服务输出失败", err)
+		}
+		if done {
+			break
+		}
+	}
+	return result, nil
+}
+
+// outputLogFiles 服务的输出日志文件，按时间从旧到新排列，当前文件在最后
+func outputLogFiles(serviceId int64) ([]string, error) {
+	name := outputLogName(serviceId)
+	backups, err := filepath.Glob(filepath.Join(outputLogDir(), name+"-*.log*"))
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeFileError, "查找服务输出文件失败", err)
+	}
+	// 备份文件名中的时间戳格式固定，按文件名排序即按时间排序
+	sort.Strings(backups)
+
+	current := outputLogPath(serviceId)
+	if _, err := os.Stat(current); err == nil {
+		backups = append(backups, current)
+	}
+	return backups, nil
+}
+
+// openOutputFile 打开输出文件，压缩的备份文件自动解压
+func openOutputFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, file}, nil
+}
+
+// readLastLines 读取文件最后 n 行，未压缩的文件从末尾倒序分块读取
+func readLastLines(path string, n int) ([]string, error) {
+	if strings.HasSuffix(path, ".gz") {
+		var lines []string
+		if _, err := scanOutputText(path, func(line string) bool {
+			lines = append(lines, line)
+			if len(lines) > n {
+				lines = lines[1:]
+			}
+			return true
+		}); err != nil {
+			return nil, err
+		}
+		return lines, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	position := info.Size()
+	for position > 0 && bytes.Count(bytes.TrimRight(data, "\n"), []byte{'\n'}) < n {
+		size := int64(outputReadChunkSize)
+		if size > position {
+			size = position
+		}
+		position -= size
+
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, position); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// scanOutputText 逐行读取文件，handler 返回 false 时停止，返回值表示是否被提前停止
+func scanOutputText(path string, handler func(line string) bool) (bool, error) {
+	reader, err := openOutputFile(path)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, outputReadChunkSize), outputScanBufSize)
+	for scanner.Scan() {
+		if !handler(scanner.Text()) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// scanOutputFile 逐行读取并解析输出文件
+func scanOutputFile(path string, handler func(line model.OutputLineModel) bool) (bool, error) {
+	return scanOutputText(path, func(line string) bool {
+		return handler(parseOutputLine(line))
+	})
+}
+
+// parseOutputLines 解析多行输出
+func parseOutputLines(lines []string) []model.OutputLineModel {
+	result := make([]model.OutputLineModel, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, parseOutputLine(line))
+	}
+	return result
+}
+
+// parseOutputLine 解析 "时间 [来源] 内容" 格式的一行，格式不符时整行作为内容
+func parseOutputLine(line string) model.OutputLineModel {
+	result := model.OutputLineModel{Text: line}
+	if len(line) < len(outputTimeLayout)+3 {
+		return result
+	}
+
+	lineTime, err := time.ParseInLocation(outputTimeLayout, line[:len(outputTimeLayout)], time.Local)
+	if err != nil {
+		return result
+	}
+
+	rest := line[len(outputTimeLayout)+1:]
+	if !strings.HasPrefix(rest, "[") {
+		return result
+	}
+	end := strings.Index(rest, "] ")
+	if end < 0 {
+		return result
+	}
+
+	result.Time = lineTime
+	result.Stream = rest[1:end]
+	result.Text = rest[end+2:]
+	return result
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"go_service/app/common"
+	"go_service/app/model"
+	"regexp"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// outputSubscriberBuffer 每个订阅者缓冲的行数，缓冲满时丢弃新行并计数
+const outputSubscriberBuffer = 256
+
+// outputHub 服务输出的订阅表，输出写入日志文件时广播给该服务的所有订阅者，
+// 多个查看者共享同一份输出，不会各自读取文件
+var outputHub = struct {
+	sync.RWMutex
+	subscribers map[int64]map[*OutputSubscription]struct{}
+}{
+	subscribers: make(map[int64]map[*OutputSubscription]struct{}),
+}
+
+// OutputSubscription 一个实时输出订阅
+type OutputSubscription struct {
+	ServiceId int64
+	Backlog   []model.OutputLineModel // 订阅时最近的输出
+	lines     chan model.OutputLineModel
+	filter    *regexp.Regexp
+	streams   map[string]bool
+
+	mutex   sync.Mutex
+	closed  bool
+	dropped int
+}
+
+// Lines 实时输出，订阅关闭后通道关闭
+func (s *OutputSubscription) Lines() <-chan model.OutputLineModel {
+	return s.lines
+}
+
+// TakeDropped 返回并清零因缓冲满而丢弃的行数
+func (s *OutputSubscription) TakeDropped() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dropped := s.dropped
+	s.dropped = 0
+	return dropped
+}
+
+// Close 取消订阅
+func (s *OutputSubscription) Close() {
+	outputHub.Lock()
+	if subscribers, ok := outputHub.subscribers[s.ServiceId]; ok {
+		delete(subscribers, s)
+		if len(subscribers) == 0 {
+			delete(outputHub.subscribers, s.ServiceId)
+		}
+	}
+	outputHub.Unlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.lines)
+	}
+}
+
+// match 是否符合订阅的来源和正则过滤条件
+func (s *OutputSubscription) match(line model.OutputLineModel) bool {
+	if len(s.streams) > 0 && !s.streams[line.Stream] {
+		return false
+	}
+	return s.filter == nil || s.filter.MatchString(line.Text)
+}
+
+// deliver 非阻塞投递，订阅者处理不及时时丢弃
+func (s *OutputSubscription) deliver(line model.OutputLineModel) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.lines <- line:
+	default:
+		s.dropped++
+	}
+}
+
+// publishOutput 向服务的订阅者广播一行输出
+func publishOutput(serviceId int64, line model.OutputLineModel) {
+	outputHub.RLock()
+	defer outputHub.RUnlock()
+
+	for subscription := range outputHub.subscribers[serviceId] {
+		if subscription.match(line) {
+			subscription.deliver(line)
+		}
+	}
+}
+
+// OutputStreamService 服务实时输出
+type OutputStreamService struct {
+	outputLogService *OutputLogService
+}
+
+func NewOutputStreamService(db *gorm.DB) *OutputStreamService {
+	return &OutputStreamService{
+		outputLogService: NewOutputLogService(db),
+	}
+}
+
+// Subscribe 订阅服务的实时输出，backlog 大于0时附带最近 backlog 行，pattern 为过滤正则，streams 为空表示全部来源
+func (s *OutputStreamService) Subscribe(ctx context.Context, serviceId int64, backlog int, pattern string, streams []string) (*OutputSubscription, error) {
+	subscription := &OutputSubscription{
+		ServiceId: serviceId,
+		Backlog:   []model.OutputLineModel{},
+		lines:     make(chan model.OutputLineModel, outputSubscriberBuffer),
+	}
+	if pattern != "" {
+		filter, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, common.WrapError(common.ErrCodeInvalidParam, "无效的过滤正则", err)
+		}
+		subscription.filter = filter
+	}
+	if len(streams) > 0 {
+		subscription.streams = make(map[string]bool, len(streams))
+		for _, stream := range streams {
+			subscription.streams[stream] = true
+		}
+	}
+
+	// 先注册再读取历史输出，避免两者之间的输出丢失
+	outputHub.Lock()
+	if outputHub.subscribers[serviceId] == nil {
+		outputHub.subscribers[serviceId] = make(map[*OutputSubscription]struct{})
+	}
+	outputHub.subscribers[serviceId][subscription] = struct{}{}
+	outputHub.Unlock()
+
+	if backlog > 0 {
+		tail, err := s.outputLogService.Tail(ctx, serviceId, backlog)
+		if err != nil {
+			subscription.Close()
+			return nil, err
+		}
+		for _, line := range tail.Lines {
+			if subscription.match(line) {
+				subscription.Backlog = append(subscription.Backlog, line)
+			}
+		}
+	} else if _, err := s.outputLogService.serviceService.GetServiceById(ctx, serviceId); err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/model"
+
+	"gorm.io/gorm"
+)
+
+// PermissionService 角色权限
+// 用户的全局角色对所有服务生效，按服务或分组授予的角色只对对应服务生效，取两者中较高的角色
+type PermissionService struct {
+	db             *gorm.DB
+	serviceService *ServiceService
+}
+
+func NewPermissionService(db *gorm.DB) *PermissionService {
+	return &PermissionService{
+		db:             db,
+		serviceService: NewServiceService(db),
+	}
+}
+
+// CheckGlobal 校验用户的全局角色
+func (p *PermissionService) CheckGlobal(user *model.UserModel, role string) error {
+	if model.RoleLevel(user.Role) < model.RoleLevel(role) {
+		return common.ErrPermissionDenied
+	}
+	return nil
+}
+
+// CheckServices 校验用户在每个服务上的角色
+func (p *PermissionService) CheckServices(ctx context.Context, user *model.UserModel, role string, serviceIds []int64) error {
+	if p.CheckGlobal(user, role) == nil {
+		return nil
+	}
+
+	grants, err := p.userGrants(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	for _, serviceId := range serviceIds {
+		service, err := p.serviceService.GetServiceById(ctx, serviceId)
+		if err != nil {
+			return err
+		}
+		if model.RoleLevel(grantedRole(grants, service.Id, service.Group)) < model.RoleLevel(role) {
+			return common.NewBusinessError(common.ErrCodePermissionDenied, fmt.Sprintf("权限不足: 无权操作服务 %s", service.Name))
+		}
+	}
+	return nil
+}
+
+// CheckGroup 校验用户在分组上的角色，用于添加服务或修改服务分组
+func (p *PermissionService) CheckGroup(ctx context.Context, user *model.UserModel, role, group string) error {
+	if p.CheckGlobal(user, role) == nil {
+		return nil
+	}
+	if group == "" {
+		return common.ErrPermissionDenied
+	}
+
+	grants, err := p.userGrants(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	if model.RoleLevel(grantedRole(grants, 0, group)) < model.RoleLevel(role) {
+		return common.ErrPermissionDenied
+	}
+	return nil
+}
+
+// Scope 用户拥有 role 及以上角色的服务范围，全局角色满足时返回 nil 表示不限制
+func (p *PermissionService) Scope(ctx context.Context, user *model.UserModel, role string) (*model.ServiceScope, error) {
+	if p.CheckGlobal(user, role) == nil {
+		return nil, nil
+	}
+
+	grants, err := p.userGrants(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+	scope := &model.ServiceScope{}
+	for _, grant := range grants {
+		if model.RoleLevel(grant.Role) < model.RoleLevel(role) {
+			continue
+		}
+		if grant.ServiceId > 0 {
+			scope.ServiceIds = append(scope.ServiceIds, grant.ServiceId)
+		} else {
+			scope.Groups = append(scope.Groups, grant.Group)
+		}
+	}
+	return scope, nil
+}
+
+// ListGrants 查询授权，userId 为 0 时查询全部
+func (p *PermissionService) ListGrants(ctx context.Context, userId int64) ([]model.UserPermissionModel, error) {
+	query := p.db.WithContext(ctx).Model(&model.UserPermissionModel{})
+	if userId > 0 {
+		query = query.Where("user_id = ?", userId)
+	}
+
+	grants := []model.UserPermissionModel{}
+	if err := query.Order("id").Find(&grants).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询授权失败", err)
+	}
+	return grants, nil
+}
+
+// Grant 授予用户在服务或分组上的角色，已有相同范围的授权时更新角色
+func (p *PermissionService) Grant(ctx context.Context, grant *model.UserPermissionModel) error {
+	if err := grant.Validate(); err != nil {
+		return common.WrapError(common.ErrCodeInvalidParam, "授权数据验证失败", err)
+	}
+
+	var count int64
+	if err := p.db.WithContext(ctx).Model(&model.UserModel{}).Where("id = ?", grant.UserId).Count(&count).Error; err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	if count == 0 {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, "用户不存在")
+	}
+	if grant.ServiceId > 0 {
+		if _, err := p.serviceService.GetServiceById(ctx, grant.ServiceId); err != nil {
+			return err
+		}
+	}
+
+	var existing model.UserPermissionModel
+	err := p.db.WithContext(ctx).
+		Where("user_id = ? AND service_id = ? AND group_name = ?", grant.UserId, grant.ServiceId, grant.Group).
+		First(&existing).Error
+	if err == nil {
+		if err := p.db.WithContext(ctx).Model(&existing).Update("role", grant.Role).Error; err != nil {
+			return common.WrapError(common.ErrCodeDatabaseError, "更新授权失败", err)
+		}
+		grant.Id = existing.Id
+		grant.CreatedAt = existing.CreatedAt
+		return nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return common.WrapError(common.ErrCodeDatabaseError, "查询授权失败", err)
+	}
+
+	if err := p.db.WithContext(ctx).Create(grant).Error; err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "创建授权失败", err)
+	}
+	return nil
+}
+
+// Revoke 删除授权
+func (p *PermissionService) Revoke(ctx context.Context, id int64) error {
+	result := p.db.WithContext(ctx).Delete(&model.UserPermissionModel{}, id)
+	if result.Error != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "删除授权失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, "授权不存在")
+	}
+	return nil
+}
+
+// userGrants 用户的全部授权
+func (p *PermissionService) userGrants(ctx context.Context, userId int64) ([]model.UserPermissionModel, error) {
+	var grants []model.UserPermissionModel
+	if err := p.db.WithContext(ctx).Where("user_id = ?", userId).Find(&grants).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询授权失败", err)
+	}
+	return grants, nil
+}
+
+// grantedRole 授权中对服务生效的最高角色
+func grantedRole(grants []model.UserPermissionModel, serviceId int64, group string) string {
+	role := ""
+	for _, grant := range grants {
+		matched := (serviceId > 0 && grant.ServiceId == serviceId) || (group != "" && grant.Group == group)
+		if matched && model.RoleLevel(grant.Role) > model.RoleLevel(role) {
+			role = grant.Role
+		}
+	}
+	return role
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go_service/app/model"
+	"go_service/pkg/utils"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 探针默认参数
+const (
+	defaultProbeTimeout          = 1 * time.Second
+	defaultProbePeriod           = 1 * time.Second
+	defaultProbeFailureThreshold = 3
+	probeLogTailSize             = 64 * 1024 // log 探针读取文件末尾的字节数
+)
+
+// probeResult 探针单次执行结果
+type probeResult struct {
+	StatusCode int           // http 探针的响应状态码
+	Latency    time.Duration // 执行耗时
+	Err        error         // 失败原因，nil 表示成功
+}
+
+var probeHTTPClient = &http.Client{}
+
+// probeTimeout 探针单次超时
+func probeTimeout(probe *model.ProbeConfig) time.Duration {
+	if probe.Timeout > 0 {
+		return time.Duration(probe.Timeout) * time.Second
+	}
+	return defaultProbeTimeout
+}
+
+// probePeriod 探针检测间隔
+func probePeriod(probe *model.ProbeConfig) time.Duration {
+	if probe.Period > 0 {
+		return time.Duration(probe.Period) * time.Second
+	}
+	return defaultProbePeriod
+}
+
+// probeFailureThreshold 探针连续失败阈值
+func probeFailureThreshold(probe *model.ProbeConfig) int {
+	if probe.FailureThreshold > 0 {
+		return probe.FailureThreshold
+	}
+	return defaultProbeFailureThreshold
+}
+
+// runProbe 执行一次探针，output 返回服务目前的输出，供 log 探针在未指定文件时匹配
+func runProbe(ctx context.Context, probe *model.ProbeConfig, service *model.ServiceModel, output func() string) probeResult {
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout(probe))
+	defer cancel()
+
+	startTime := time.Now()
+	var result probeResult
+	switch probe.Type {
+	case model.ProbeTypeTCP:
+		result.Err = probeTCP(probeCtx, probe, service)
+	case model.ProbeTypeHTTP:
+		result.StatusCode, result.Err = probeHTTP(probeCtx, probe)
+	case model.ProbeTypeExec:
+		result.Err = probeExec(probeCtx, probe, service)
+	case model.ProbeTypeLog:
+		result.Err = probeLog(probe, output())
+	default:
+		result.Err = fmt.Errorf("不支持的探针类型: %s", probe.Type)
+	}
+	result.Latency = time.Since(startTime)
+	return result
+}
+
+// waitForProbe 按探针的间隔重复执行，成功返回 nil，连续失败达到阈值时返回最后一次错误
+func waitForProbe(ctx context.Context, probe *model.ProbeConfig, service *model.ServiceModel, output func() string) error {
+	threshold := probeFailureThreshold(probe)
+	period := probePeriod(probe)
+
+	failures := 0
+	for {
+		result := runProbe(ctx, probe, service, output)
+		if result.Err == nil {
+			return nil
+		}
+
+		failures++
+		if failures >= threshold {
+			return fmt.Errorf("%s探针连续 %d 次失败: %v", probe.Type, failures, result.Err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s探针等待被取消: %v", probe.Type, ctx.Err())
+		case <-time.After(period):
+		}
+	}
+}
+
+// probeTCP 连接端口
+func probeTCP(ctx context.Context, probe *model.ProbeConfig, service *model.ServiceModel) error {
+	host := probe.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	port := probe.Port
+	if port == 0 {
+		port = service.Port
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.FormatInt(port, 10)))
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+// probeHTTP 请求地址并校验状态码、响应体和响应头
+func probeHTTP(ctx context.Context, probe *model.ProbeConfig) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("无效的探针地址: %v", err)
+	}
+
+	resp, err := probeHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	if probe.ExpectStatus > 0 {
+		if resp.StatusCode != probe.ExpectStatus {
+			return resp.StatusCode, fmt.Errorf("状态码 %d 不等于期望值 %d", resp.StatusCode, probe.ExpectStatus)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("返回状态码 %d", resp.StatusCode)
+	}
+
+	if probe.ExpectBody != "" && !bytes.Contains(body, []byte(probe.ExpectBody)) {
+		return resp.StatusCode, fmt.Errorf("响应体不包含 %q", probe.ExpectBody)
+	}
+
+	for key, value := range probe.ExpectHeaders {
+		if actual := resp.Header.Get(key); actual != value {
+			return resp.StatusCode, fmt.Errorf("响应头 %s 为 %q，期望 %q", key, actual, value)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// probeExec 在服务目录执行命令，退出码为0视为成功
+func probeExec(ctx context.Context, probe *model.ProbeConfig, service *model.ServiceModel) error {
+	if err := utils.ValidateCommand(probe.Command); err != nil {
+		return fmt.Errorf("命令安全验证失败: %v", err)
+	}
+
+	args := strings.Fields(utils.SanitizeCommand(probe.Command))
+	if len(args) == 0 {
+		return fmt.Errorf("无效的命令")
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if service.Dir != "" {
+		cmd.Dir = service.Dir
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("命令执行超时")
+		}
+		return fmt.Errorf("命令执行失败: %v, 输出: %s", err, truncateString(string(output), 500))
+	}
+	return nil
+}
+
+// probeLog 检查输出中是否出现指定正则
+func probeLog(probe *model.ProbeConfig, output string) error {
+	pattern, err := regexp.Compile(probe.Pattern)
+	if err != nil {
+		return fmt.Errorf("无效的正则: %v", err)
+	}
+
+	content := output
+	if probe.LogFile != "" {
+		content, err = readFileTail(probe.LogFile, probeLogTailSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !pattern.MatchString(content) {
+		return fmt.Errorf("输出中未匹配到 %q", probe.Pattern)
+	}
+	return nil
+}
+
+// readFileTail 读取文件末尾最多 size 字节
+func readFileTail(path string, size int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("读取日志文件失败: %v", err)
+	}
+
+	offset := info.Size() - size
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && err != io.EOF {
+		return "", fmt.Errorf("读取日志文件失败: %v", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go_service/app/model"
+	"go_service/pkg/utils"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// managedProcess 托管模式下由 go_service 启动并回收的进程
+type managedProcess struct {
+	serviceId int64
+	cmd       *exec.Cmd
+	output    *outputBuffer
+	stdout    *outputStreamWriter // 写入服务输出日志
+	stderr    *outputStreamWriter
+	done      chan struct{} // 进程退出后关闭
+
+	mutex     sync.RWMutex
+	pid       int
+	pgid      int
+	startedAt time.Time
+	exited    bool
+	exitedAt  time.Time
+	exitCode  int
+	signal    string
+}
+
+// processManager 托管进程表，按服务ID索引
+var processManager = struct {
+	sync.RWMutex
+	processes map[int64]*managedProcess
+}{
+	processes: make(map[int64]*managedProcess),
+}
+
+// managedStopTimeout 发送 SIGTERM 后等待进程组退出的时间，超时后发送 SIGKILL
+const managedStopTimeout = 10 * time.Second
+
+// outputBufferSize 托管进程保留的输出大小，用于启动结果和 log 探针
+const outputBufferSize = 64 * 1024
+
+// outputBuffer 只保留最近输出的并发安全缓冲区
+type outputBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.buf.Write(p)
+	if overflow := b.buf.Len() - outputBufferSize; overflow > 0 {
+		b.buf.Next(overflow)
+	}
+	return len(p), nil
+}
+
+func (b *outputBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// isManaged 服务是否使用托管模式运行
+func isManaged(service *model.ServiceModel) bool {
+	return service.RunMode == model.RunModeManaged
+}
+
+// spawnManagedProcess 启动并托管服务进程，进程放入独立进程组，退出后由后台协程回收
+func spawnManagedProcess(service *model.ServiceModel) (*managedProcess, error) {
+	if current := getManagedProcess(service.Id); current != nil && !current.isExited() {
+		return nil, fmt.Errorf("托管进程 %d 仍在运行", current.pid)
+	}
+
+	// 托管进程长期运行，不随请求上下文取消
+	cmd, err := buildCommand(context.Background(), service.CmdStart, service.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	process := &managedProcess{
+		serviceId: service.Id,
+		cmd:       cmd,
+		output:    &outputBuffer{},
+		stdout:    newOutputStreamWriter(service.Id, OutputStreamStdout),
+		stderr:    newOutputStreamWriter(service.Id, OutputStreamStderr),
+		done:      make(chan struct{}),
+	}
+	cmd.Stdout = io.MultiWriter(process.output, process.stdout)
+	cmd.Stderr = io.MultiWriter(process.output, process.stderr)
+	// 主进程退出后不再等待仍持有输出管道的子进程
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Start(); err != nil {
+		recordOutputEvent(service.Id, "启动进程失败: %v", err)
+		return nil, fmt.Errorf("启动进程失败: %v", err)
+	}
+
+	process.pid = cmd.Process.Pid
+	process.pgid = cmd.Process.Pid // Setpgid 后进程组ID与进程ID相同
+	process.startedAt = time.Now()
+	recordOutputEvent(service.Id, "托管进程已启动，PID: %d, 命令: %s", process.pid, service.CmdStart)
+
+	processManager.Lock()
+	processManager.processes[service.Id] = process
+	processManager.Unlock()
+
+	go process.wait()
+	return process, nil
+}
+
+// wait 回收进程并记录退出码和信号
+func (p *managedProcess) wait() {
+	err := p.cmd.Wait()
+
+	p.mutex.Lock()
+	p.exited = true
+	p.exitedAt = time.Now()
+	p.exitCode = -1
+	if state := p.cmd.ProcessState; state != nil {
+		p.exitCode = state.ExitCode()
+		if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			p.signal = status.Signal().String()
+		}
+	} else if err != nil {
+		p.signal = err.Error()
+	}
+	p.mutex.Unlock()
+
+	p.stdout.Flush()
+	p.stderr.Flush()
+	recordOutputEvent(p.serviceId, "%s", p.exitDescription())
+	// 非主动停止且不在操作中的退出视为异常停止
+	if !isManualStopped(p.serviceId) && !isOperating(p.serviceId, 0) {
+		sendAlert(model.AlertEvent{
+			Type:      model.AlertServiceDown,
+			Level:     model.AlertLevelCritical,
+			ServiceId: p.serviceId,
+			Message:   p.exitDescription(),
+		})
+	}
+
+	close(p.done)
+}
+
+// isExited 进程是否已退出
+func (p *managedProcess) isExited() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.exited
+}
+
+// signalGroup 向整个进程组发送信号
+func (p *managedProcess) signalGroup(sig syscall.Signal) error {
+	if p.isExited() {
+		return nil
+	}
+	if err := syscall.Kill(-p.pgid, sig); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("向进程组 %d 发送 %s 失败: %v", p.pgid, sig, err)
+	}
+	return nil
+}
+
+// terminate 先发送 SIGTERM，超时后发送 SIGKILL，force 为 true 时直接 SIGKILL
+func (p *managedProcess) terminate(force bool) (string, error) {
+	if !force {
+		if err := p.signalGroup(syscall.SIGTERM); err != nil {
+			return "", err
+		}
+		select {
+		case <-p.done:
+			return fmt.Sprintf("进程组 %d 已通过 SIGTERM 停止", p.pgid), nil
+		case <-time.After(managedStopTimeout):
+		}
+	}
+
+	if err := p.signalGroup(syscall.SIGKILL); err != nil {
+		return "", err
+	}
+	select {
+	case <-p.done:
+		return fmt.Sprintf("进程组 %d 已通过 SIGKILL 终止", p.pgid), nil
+	case <-time.After(5 * time.Second):
+		return "", fmt.Errorf("等待进程 %d 退出超时", p.pid)
+	}
+}
+
+// info 转换为接口返回的模型
+func (p *managedProcess) info() *model.ManagedProcessModel {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	info := &model.ManagedProcessModel{
+		Pid:       p.pid,
+		Pgid:      p.pgid,
+		StartedAt: p.startedAt,
+		Exited:    p.exited,
+	}
+	if p.exited {
+		exitCode := p.exitCode
+		exitedAt := p.exitedAt
+		info.ExitCode = &exitCode
+		info.ExitSignal = p.signal
+		info.ExitedAt = &exitedAt
+	}
+	return info
+}
+
+// exitDescription 进程退出信息描述
+func (p *managedProcess) exitDescription() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.exited {
+		return fmt.Sprintf("进程 %d 运行中", p.pid)
+	}
+	if p.signal != "" {
+		return fmt.Sprintf("进程 %d 被信号 %s 终止", p.pid, p.signal)
+	}
+	return fmt.Sprintf("进程 %d 退出，退出码 %d", p.pid, p.exitCode)
+}
+
+// getManagedProcess 获取服务的托管进程，不存在时返回 nil
+func getManagedProcess(serviceId int64) *managedProcess {
+	processManager.RLock()
+	defer processManager.RUnlock()
+	return processManager.processes[serviceId]
+}
+
+// getRunningManagedProcess 获取服务仍在运行的托管进程
+func getRunningManagedProcess(serviceId int64) *managedProcess {
+	if process := getManagedProcess(serviceId); process != nil && !process.isExited() {
+		return process
+	}
+	return nil
+}
+
+// isServiceRunning 判断服务是否运行：托管进程仍在运行时视为运行，否则检测端口
+// 托管的主进程退出后，派生的子进程可能仍占用端口，此时同样视为运行
+func isServiceRunning(service *model.ServiceModel) bool {
+	return isServiceListening(service, systemPortInspector{})
+}
+
+// isServiceListening 使用指定的端口查询判断服务是否运行
+func isServiceListening(service *model.ServiceModel, ports PortInspector) bool {
+	return getRunningManagedProcess(service.Id) != nil || ports.IsListening(service.Port)
+}
+
+// buildCommand 构建命令，设置工作目录、独立进程组和受限环境变量
+func buildCommand(ctx context.Context, command, workDir string) (*exec.Cmd, error) {
+	if command == "" {
+		return nil, fmt.Errorf("命令不能为空")
+	}
+
+	// 验证命令安全性
+	if err := utils.ValidateCommand(command); err != nil {
+		return nil, fmt.Errorf("命令安全验证失败: %v", err)
+	}
+
+	// 验证工作目录
+	if workDir != "" {
+		if err := utils.IsValidDirectory(workDir); err != nil {
+			return nil, fmt.Errorf("工作目录验证失败: %v", err)
+		}
+	}
+
+	// 清理命令
+	command = utils.SanitizeCommand(command)
+
+	var name string
+	var args []string
+
+	// 根据命令类型选择执行方式
+	if strings.Contains(command, "&&") || strings.Contains(command, "||") || strings.Contains(command, ";") {
+		// 复杂命令，使用bash执行
+		name, args = "bash", []string{"-c", command}
+	} else {
+		// 简单命令，直接分割参数
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("无效的命令")
+		}
+		name, args = fields[0], fields[1:]
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	// 设置工作目录
+	if workDir != "" {
+		cmd.Dir = workDir
+	}
+
+	// 设置子进程独立于父进程
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true, // 创建新进程组
+	}
+
+	// 设置环境变量限制
+	cmd.Env = append(os.Environ(),
+		"PATH=/usr/local/bin:/usr/bin:/bin", // 限制PATH
+		"SHELL=/bin/bash",                   // 固定shell
+	)
+
+	return cmd, nil
+}
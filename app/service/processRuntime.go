@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go_service/pkg/utils"
+	"strconv"
+	"syscall"
+)
+
+// Executor 执行服务的启动、停止和重启命令
+type Executor interface {
+	// Run 在工作目录中执行命令并等待结束，返回标准输出和标准错误的合并内容，ctx 取消时终止命令
+	Run(ctx context.Context, command, workDir string) (string, error)
+}
+
+// PortInspector 查询端口监听状态和监听端口的进程
+type PortInspector interface {
+	// IsListening 端口是否正在被监听
+	IsListening(port int64) bool
+	// ListenerPid 监听端口的进程ID，端口未被监听或无法获取进程时返回错误
+	ListenerPid(port int64) (int, error)
+}
+
+// ProcessKiller 向进程发送信号
+type ProcessKiller interface {
+	Kill(pid int, sig syscall.Signal) error
+}
+
+// commandExecutor 使用 bash 或直接执行命令
+type commandExecutor struct{}
+
+func (commandExecutor) Run(ctx context.Context, command, workDir string) (string, error) {
+	cmd, err := buildCommand(ctx, command, workDir)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// systemPortInspector 通过连接端口检测监听状态，通过 /proc 中的监听列表查找进程
+type systemPortInspector struct{}
+
+func (systemPortInspector) IsListening(port int64) bool {
+	running, _ := utils.IsPortInUse(strconv.FormatInt(port, 10))
+	return running
+}
+
+func (systemPortInspector) ListenerPid(port int64) (int, error) {
+	portList, err := utils.GetPortList()
+	if err != nil {
+		return 0, err
+	}
+	listener, ok := portList[port]
+	if !ok {
+		return 0, errors.New("端口未被占用")
+	}
+	if listener.Pid <= 0 {
+		return 0, errors.New("无法获取进程ID")
+	}
+	return listener.Pid, nil
+}
+
+// signalKiller 使用系统调用发送信号
+type signalKiller struct{}
+
+func (signalKiller) Kill(pid int, sig syscall.Signal) error {
+	// 进程可能已退出，端口列表需重新读取
+	defer utils.ClearPortListCache()
+	return syscall.Kill(pid, sig)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// fakeCommand 编排的命令执行结果及其对端口的影响
+type fakeCommand struct {
+	output  string
+	err     error
+	delay   time.Duration // 执行耗时，ctx 取消时提前返回
+	listen  int64         // 执行后开始监听的端口
+	release int64         // 执行后停止监听的端口
+}
+
+// fakeSignal 发送给进程的信号
+type fakeSignal struct {
+	pid int
+	sig syscall.Signal
+}
+
+// fakeRuntime 可编排的命令执行、端口查询和进程终止，进程只存在于内存中
+type fakeRuntime struct {
+	mutex      sync.Mutex
+	commands   map[string]fakeCommand
+	listeners  map[int64]int // 端口 -> 进程ID
+	ignoreTerm map[int]bool  // 忽略 SIGTERM 的进程
+	killErr    error         // 发送信号失败时返回的错误
+	nextPid    int
+	executed   []string
+	signals    []fakeSignal
+	running    int
+	maxRunning int // 同时执行的命令数的最大值
+	runningBy  map[string]int
+	maxSame    int // 同一命令同时执行数的最大值
+}
+
+func newFakeRuntime() *fakeRuntime {
+	return &fakeRuntime{
+		commands:   make(map[string]fakeCommand),
+		listeners:  make(map[int64]int),
+		ignoreTerm: make(map[int]bool),
+		runningBy:  make(map[string]int),
+		nextPid:    1000,
+	}
+}
+
+// script 编排命令的执行结果
+func (f *fakeRuntime) script(command string, result fakeCommand) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.commands[command] = result
+}
+
+// listen 模拟一个进程开始监听端口，返回进程ID
+func (f *fakeRuntime) listen(port int64) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.listenLocked(port)
+}
+
+func (f *fakeRuntime) listenLocked(port int64) int {
+	f.nextPid++
+	f.listeners[port] = f.nextPid
+	return f.nextPid
+}
+
+// executedCommands 已执行的命令
+func (f *fakeRuntime) executedCommands() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]string(nil), f.executed...)
+}
+
+// sentSignals 已发送的信号
+func (f *fakeRuntime) sentSignals() []fakeSignal {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]fakeSignal(nil), f.signals...)
+}
+
+func (f *fakeRuntime) Run(ctx context.Context, command, workDir string) (string, error) {
+	f.mutex.Lock()
+	f.executed = append(f.executed, command)
+	f.running++
+	if f.running > f.maxRunning {
+		f.maxRunning = f.running
+	}
+	f.runningBy[command]++
+	if f.runningBy[command] > f.maxSame {
+		f.maxSame = f.runningBy[command]
+	}
+	result, ok := f.commands[command]
+	f.mutex.Unlock()
+
+	defer func() {
+		f.mutex.Lock()
+		f.running--
+		f.runningBy[command]--
+		f.mutex.Unlock()
+	}()
+
+	if !ok {
+		return "", fmt.Errorf("未编排的命令: %s", command)
+	}
+	if result.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return result.output, ctx.Err()
+		case <-time.After(result.delay):
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if result.release > 0 {
+		delete(f.listeners, result.release)
+	}
+	if result.listen > 0 {
+		f.listenLocked(result.listen)
+	}
+	return result.output, result.err
+}
+
+func (f *fakeRuntime) IsListening(port int64) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, ok := f.listeners[port]
+	return ok
+}
+
+func (f *fakeRuntime) ListenerPid(port int64) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	pid, ok := f.listeners[port]
+	if !ok {
+		return 0, errors.New("端口未被占用")
+	}
+	return pid, nil
+}
+
+func (f *fakeRuntime) Kill(pid int, sig syscall.Signal) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.signals = append(f.signals, fakeSignal{pid: pid, sig: sig})
+	if f.killErr != nil {
+		return f.killErr
+	}
+	if sig == syscall.SIGTERM && f.ignoreTerm[pid] {
+		return nil
+	}
+	for port, listener := range f.listeners {
+		if listener == pid {
+			delete(f.listeners, port)
+			return nil
+		}
+	}
+	return syscall.ESRCH
+}
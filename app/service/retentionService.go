@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/config"
+	"go_service/app/model"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// retentionCheckInterval 检查是否到达每天清理时间的间隔
+const retentionCheckInterval = time.Minute
+
+// retentionJob 正在执行的清理任务，定时和手动触发共用，同一时间只执行一个
+var retentionJob struct {
+	sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// RetentionService 操作日志清理
+// 每天在 monitor.retention_time 删除超过保留天数的操作日志，服务可单独设置保留天数，每个服务至少保留最近的 retention_keep_last 条
+// 配置 retention_archive_dir 时删除前先归档为 jsonl.gz，每次清理的结果记录在 log_retention_run 表中
+type RetentionService struct {
+	db          *gorm.DB
+	logService  *LogService
+	stopChannel chan struct{}
+	wg          sync.WaitGroup
+}
+
+func NewRetentionService(db *gorm.DB) *RetentionService {
+	return &RetentionService{
+		db:          db,
+		logService:  NewLogService(db),
+		stopChannel: make(chan struct{}),
+	}
+}
+
+// Start 启动定时清理，本进程启动时当天的清理已过时间且未执行过时立即执行
+func (r *RetentionService) Start() {
+	var lastDay string
+	var last model.LogRetentionRunModel
+	if err := r.db.Where("trigger_type = ?", "schedule").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		log.Printf("查询日志清理记录失败: %v", err)
+	} else if last.Id > 0 {
+		lastDay = last.StartedAt.Format("2006-01-02")
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(retentionCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			now := time.Now()
+			if today := now.Format("2006-01-02"); today != lastDay && retentionDue(now) && config.GlobalConfig.Monitor.RetentionDays > 0 {
+				lastDay = today
+				if _, err := r.Run("schedule"); err != nil {
+					log.Printf("定时清理操作日志失败: %v", err)
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-r.stopChannel:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定时清理，并取消正在执行的清理任务
+func (r *RetentionService) Stop() {
+	close(r.stopChannel)
+	r.wg.Wait()
+
+	retentionJob.Lock()
+	cancel, done := retentionJob.cancel, retentionJob.done
+	retentionJob.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Run 在后台执行一次清理，返回清理记录，已有清理任务在执行时返回错误
+func (r *RetentionService) Run(trigger string) (*model.LogRetentionRunModel, error) {
+	monitor := config.GlobalConfig.Monitor
+	if monitor.RetentionDays <= 0 {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "未开启日志清理(retention_days 为 0)")
+	}
+
+	retentionJob.Lock()
+	defer retentionJob.Unlock()
+	if retentionJob.cancel != nil {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "日志清理正在执行")
+	}
+
+	run := &model.LogRetentionRunModel{
+		Trigger:       trigger,
+		Status:        model.RetentionStatusRunning,
+		RetentionDays: monitor.RetentionDays,
+		KeepLast:      monitor.RetentionKeepLast,
+		Detail:        map[int64]int64{},
+		StartedAt:     time.Now(),
+	}
+	if err := r.db.Create(run).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "记录日志清理失败", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	retentionJob.cancel, retentionJob.done = cancel, done
+
+	result := *run
+	result.Detail = map[int64]int64{}
+	go func() {
+		defer func() {
+			retentionJob.Lock()
+			retentionJob.cancel, retentionJob.done = nil, nil
+			retentionJob.Unlock()
+			cancel()
+			close(done)
+		}()
+		r.execute(ctx, &result, monitor)
+	}()
+	return run, nil
+}
+
+// ListRuns 最近的清理记录
+func (r *RetentionService) ListRuns(ctx context.Context, limit int) ([]model.LogRetentionRunModel, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	runs := []model.LogRetentionRunModel{}
+	if err := r.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询日志清理记录失败", err)
+	}
+	return runs, nil
+}
+
+// execute 按服务清理并保存结果
+func (r *RetentionService) execute(ctx context.Context, run *model.LogRetentionRunModel, monitor config.MonitorConfig) {
+	err := r.clean(ctx, run, monitor)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = model.RetentionStatusSuccess
+	if err != nil {
+		run.Status = model.RetentionStatusFailed
+		run.Error = err.Error()
+		log.Printf("清理操作日志失败: %v", err)
+	}
+	log.Printf("清理操作日志完成: 删除 %d 条，归档 %d 条，耗时 %v", run.DeletedCount, run.ArchivedCount, finishedAt.Sub(run.StartedAt).Round(time.Millisecond))
+
+	if err := r.db.Save(run).Error; err != nil {
+		log.Printf("保存日志清理记录失败: %v", err)
+	}
+}
+
+// clean 逐个服务删除过期日志，已删除服务的日志使用全局保留天数
+func (r *RetentionService) clean(ctx context.Context, run *model.LogRetentionRunModel, monitor config.MonitorConfig) error {
+	var serviceIds []int64
+	if err := r.db.WithContext(ctx).Model(&model.ServiceLog{}).Distinct("service_id").Pluck("service_id", &serviceIds).Error; err != nil {
+		return fmt.Errorf("查询日志服务列表失败: %v", err)
+	}
+
+	var services []model.ServiceModel
+	if err := r.db.WithContext(ctx).Select("id", "retention_days").Find(&services).Error; err != nil {
+		return fmt.Errorf("查询服务失败: %v", err)
+	}
+	overrides := make(map[int64]int, len(services))
+	for _, service := range services {
+		overrides[service.Id] = service.RetentionDays
+	}
+
+	var archive *logArchive
+	if monitor.RetentionArchiveDir != "" {
+		var err error
+		if archive, err = newLogArchive(monitor.RetentionArchiveDir, run); err != nil {
+			return err
+		}
+		defer func() {
+			if err := archive.close(); err != nil {
+				log.Printf("关闭日志归档文件失败: %v", err)
+			}
+			if run.ArchivedCount == 0 {
+				os.Remove(archive.path)
+				run.ArchiveFile = ""
+			}
+		}()
+		run.ArchiveFile = archive.path
+	}
+
+	for _, serviceId := range serviceIds {
+		days := monitor.RetentionDays
+		if override := overrides[serviceId]; override < 0 {
+			continue
+		} else if override > 0 {
+			days = override
+		}
+
+		var archiveBatch func([]model.ServiceLog) error
+		if archive != nil {
+			archiveBatch = func(batch []model.ServiceLog) error {
+				if err := archive.write(batch); err != nil {
+					return err
+				}
+				run.ArchivedCount += int64(len(batch))
+				return nil
+			}
+		}
+
+		cutoff := run.StartedAt.AddDate(0, 0, -days)
+		deleted, err := r.logService.CleanOldLogs(ctx, serviceId, cutoff, monitor.RetentionKeepLast, archiveBatch)
+		if deleted > 0 {
+			run.Detail[serviceId] = deleted
+			run.DeletedCount += deleted
+		}
+		if err != nil {
+			return fmt.Errorf("清理服务 %d 的日志失败: %v", serviceId, err)
+		}
+	}
+	return nil
+}
+
+// retentionDue 当前时间是否已到每天的清理时间
+func retentionDue(now time.Time) bool {
+	at, err := time.Parse("15:04", config.GlobalConfig.Monitor.RetentionTime)
+	if err != nil {
+		return false
+	}
+	return now.Hour()*60+now.Minute() >= at.Hour()*60+at.Minute()
+}
+
+// logArchive 归档文件，每行一条 JSON 格式的日志，gzip 压缩
+type logArchive struct {
+	path   string
+	file   *os.File
+	gzip   *gzip.Writer
+	writer *bufio.Writer
+}
+
+// newLogArchive 创建本次清理的归档文件
+func newLogArchive(dir string, run *model.LogRetentionRunModel) (*logArchive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建归档目录失败: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("service_log-%s-%d.jsonl.gz", run.StartedAt.Format("20060102-150405"), run.Id))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("创建归档文件失败: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	return &logArchive{path: path, file: file, gzip: gz, writer: bufio.NewWriter(gz)}, nil
+}
+
+// write 写入一批日志并落盘，返回后才能删除这批日志
+func (a *logArchive) write(batch []model.ServiceLog) error {
+	encoder := json.NewEncoder(a.writer)
+	for i := range batch {
+		if err := encoder.Encode(&batch[i]); err != nil {
+			return err
+		}
+	}
+	if err := a.writer.Flush(); err != nil {
+		return err
+	}
+	if err := a.gzip.Flush(); err != nil {
+		return err
+	}
+	return a.file.Sync()
+}
+
+// close 写入 gzip 结尾并关闭文件
+func (a *logArchive) close() error {
+	if err := a.gzip.Close(); err != nil {
+		a.file.Close()
+		return err
+	}
+	return a.file.Close()
+}
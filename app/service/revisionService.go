@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/model"
+
+	"gorm.io/gorm"
+)
+
+// RevisionService 服务配置的历史版本，查看差异和恢复
+type RevisionService struct {
+	db             *gorm.DB
+	serviceService *ServiceService
+}
+
+func NewRevisionService(db *gorm.DB) *RevisionService {
+	return &RevisionService{
+		db:             db,
+		serviceService: NewServiceService(db),
+	}
+}
+
+// ListRevisions 服务的所有版本，按版本号倒序，服务已删除时仍可查询
+func (r *RevisionService) ListRevisions(ctx context.Context, serviceId int64) ([]model.ServiceRevisionModel, error) {
+	revisions := []model.ServiceRevisionModel{}
+	if err := r.db.WithContext(ctx).Where("service_id = ?", serviceId).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务版本失败", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision 获取服务的一个版本
+func (r *RevisionService) GetRevision(ctx context.Context, serviceId int64, revision int) (*model.ServiceRevisionModel, error) {
+	var result model.ServiceRevisionModel
+	if err := r.db.WithContext(ctx).Where("service_id = ? AND revision = ?", serviceId, revision).First(&result).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, common.NewBusinessError(common.ErrCodeInvalidParam, fmt.Sprintf("服务 %d 的版本 %d 不存在", serviceId, revision))
+		}
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务版本失败", err)
+	}
+	return &result, nil
+}
+
+// DiffRevisions 比较服务的两个版本，to 为 0 时与最新版本比较
+func (r *RevisionService) DiffRevisions(ctx context.Context, serviceId int64, from, to int) (*model.ServiceRevisionDiff, error) {
+	if to == 0 {
+		latest, err := latestRevision(r.db.WithContext(ctx), serviceId)
+		if err != nil {
+			return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务版本失败", err)
+		}
+		to = latest
+	}
+
+	fromRevision, err := r.GetRevision(ctx, serviceId, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := r.GetRevision(ctx, serviceId, to)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ServiceRevisionDiff{
+		ServiceId: serviceId,
+		From:      from,
+		To:        to,
+		Changes:   model.DiffServices(fromRevision.Snapshot, toRevision.Snapshot),
+	}, nil
+}
+
+// ListDeleted 已删除的服务，返回每个服务删除时的版本
+func (r *RevisionService) ListDeleted(ctx context.Context) ([]model.ServiceRevisionModel, error) {
+	revisions := []model.ServiceRevisionModel{}
+	err := r.db.WithContext(ctx).
+		Where("action = ?", model.RevisionActionDelete).
+		Where("service_id NOT IN (?)", r.db.Model(&model.ServiceModel{}).Select("id")).
+		Where("revision = (SELECT MAX(latest.revision) FROM service_revision latest WHERE latest.service_id = service_revision.service_id)").
+		Order("id DESC").Find(&revisions).Error
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询已删除的服务失败", err)
+	}
+	return revisions, nil
+}
+
+// Restore 将服务配置恢复为指定版本并保存为新版本，服务已删除时按原ID重新添加(按服务的授权不会恢复)
+func (r *RevisionService) Restore(ctx context.Context, serviceId int64, revision int) (*model.ServiceModel, error) {
+	target, err := r.GetRevision(ctx, serviceId, revision)
+	if err != nil {
+		return nil, err
+	}
+	if target.Snapshot == nil {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "该版本没有配置快照")
+	}
+
+	// 服务已删除时最新版本为删除时的配置
+	var latest model.ServiceRevisionModel
+	if err := r.db.WithContext(ctx).Where("service_id = ?", serviceId).Order("revision DESC").First(&latest).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务版本失败", err)
+	}
+
+	// 检查和写入与添加、修改服务使用同一把锁，避免并发占用相同的端口或名称
+	restored := *target.Snapshot
+	restored.Id = serviceId
+	comment := fmt.Sprintf("恢复到版本 %d", revision)
+	if err := r.serviceService.RestoreService(ctx, &restored, latest.Snapshot, comment); err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// latestRevision 服务最新的版本号，没有版本时为 0
+func latestRevision(db *gorm.DB, serviceId int64) (int, error) {
+	var latest int
+	err := db.Model(&model.ServiceRevisionModel{}).Where("service_id = ?", serviceId).
+		Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error
+	return latest, err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"go_service/app/common"
+	"go_service/app/model"
+	"sync"
+	"time"
+)
+
+// runtimeState 记录服务的运行期状态：通过 CommandService 发起的操作、健康检查结果等，
+// 供后台协程与接口共享。CommandService 在各控制器中是独立实例，因此状态放在包级别。
+var runtimeState = struct {
+	sync.RWMutex
+	manualStopped map[int64]bool                      // 通过 stop/kill 主动停止的服务
+	operating     map[int64]int                       // 正在执行操作的服务(计数，支持嵌套调用)
+	lastOperation map[int64]time.Time                 // 最近一次操作结束时间
+	health        map[int64]*model.ServiceHealthModel // 最近一次健康检查结果
+	shuttingDown  bool                                // 正在退出，不再开始新的操作
+}{
+	manualStopped: make(map[int64]bool),
+	operating:     make(map[int64]int),
+	lastOperation: make(map[int64]time.Time),
+	health:        make(map[int64]*model.ServiceHealthModel),
+}
+
+// errShuttingDown 退出过程中拒绝新的操作
+var errShuttingDown = common.NewBusinessError(common.ErrCodeNotReady, "服务管理工具正在退出")
+
+// beginOperation 标记服务开始执行操作，返回结束函数
+// 退出过程中拒绝新的操作，已在操作中的服务仍可嵌套调用，如重启中的停止和启动
+func beginOperation(serviceId int64) (func(), error) {
+	runtimeState.Lock()
+	if runtimeState.shuttingDown && runtimeState.operating[serviceId] == 0 {
+		runtimeState.Unlock()
+		return nil, errShuttingDown
+	}
+	// 在锁内登记，保证 stopOperations 之后计数不会从零增加
+	operations.Add(1)
+	runtimeState.operating[serviceId]++
+	runtimeState.Unlock()
+
+	return func() {
+		defer operations.Done()
+		runtimeState.Lock()
+		defer runtimeState.Unlock()
+		runtimeState.operating[serviceId]--
+		if runtimeState.operating[serviceId] <= 0 {
+			delete(runtimeState.operating, serviceId)
+		}
+		runtimeState.lastOperation[serviceId] = time.Now()
+	}, nil
+}
+
+// stopOperations 标记正在退出，之后不再开始新的操作
+func stopOperations() {
+	runtimeState.Lock()
+	defer runtimeState.Unlock()
+	runtimeState.shuttingDown = true
+}
+
+// isOperating 服务是否正在执行操作，或在 grace 时间内刚执行过操作
+func isOperating(serviceId int64, grace time.Duration) bool {
+	runtimeState.RLock()
+	defer runtimeState.RUnlock()
+
+	if runtimeState.operating[serviceId] > 0 {
+		return true
+	}
+	if last, ok := runtimeState.lastOperation[serviceId]; ok && time.Since(last) < grace {
+		return true
+	}
+	return false
+}
+
+// markManualStop 标记服务被主动停止
+func markManualStop(serviceId int64) {
+	runtimeState.Lock()
+	defer runtimeState.Unlock()
+	runtimeState.manualStopped[serviceId] = true
+}
+
+// clearManualStop 清除主动停止标记
+func clearManualStop(serviceId int64) {
+	runtimeState.Lock()
+	defer runtimeState.Unlock()
+	delete(runtimeState.manualStopped, serviceId)
+}
+
+// isManualStopped 服务是否被主动停止
+func isManualStopped(serviceId int64) bool {
+	runtimeState.RLock()
+	defer runtimeState.RUnlock()
+	return runtimeState.manualStopped[serviceId]
+}
+
+// getHealthResult 获取最近一次健康检查结果的副本
+func getHealthResult(serviceId int64) *model.ServiceHealthModel {
+	runtimeState.RLock()
+	defer runtimeState.RUnlock()
+
+	result, ok := runtimeState.health[serviceId]
+	if !ok {
+		return nil
+	}
+	copied := *result
+	return &copied
+}
+
+// setHealthResult 保存健康检查结果，并根据上一次结果累计连续失败次数
+func setHealthResult(serviceId int64, result *model.ServiceHealthModel) {
+	runtimeState.Lock()
+	defer runtimeState.Unlock()
+
+	if !result.Healthy {
+		result.ConsecutiveFailures = 1
+		if last, ok := runtimeState.health[serviceId]; ok {
+			result.ConsecutiveFailures = last.ConsecutiveFailures + 1
+		}
+	}
+	runtimeState.health[serviceId] = result
+}
+
+// clearHealthResult 清除健康检查结果
+func clearHealthResult(serviceId int64) {
+	runtimeState.Lock()
+	defer runtimeState.Unlock()
+	delete(runtimeState.health, serviceId)
+}
@@ -2,25 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go_service/app/common"
 	"go_service/app/model"
+	"go_service/app/repository"
 	"go_service/pkg/utils"
+	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// serviceMutex 所有 ServiceService 共用的读写锁，各控制器和版本恢复中的实例检查端口、名称和写入时同样互斥
+var serviceMutex sync.RWMutex
+
 type ServiceService struct {
-	db    *gorm.DB
-	mutex sync.RWMutex // 读写锁保护并发操作
+	services repository.ServiceRepository
+	mutex    *sync.RWMutex // 读写锁保护并发操作
 }
 
 func NewServiceService(db *gorm.DB) *ServiceService {
+	return NewServiceServiceWithRepository(repository.NewGormServiceRepository(db))
+}
+
+// NewServiceServiceWithRepository 使用指定的存储创建服务管理，测试时可使用内存存储
+func NewServiceServiceWithRepository(services repository.ServiceRepository) *ServiceService {
 	return &ServiceService{
-		db: db,
+		services: services,
+		mutex:    &serviceMutex,
 	}
 }
 
@@ -35,18 +49,20 @@ func (s *ServiceService) CreateService(ctx context.Context, service *model.Servi
 	}
 
 	// 检查端口是否已被占用
-	if err := s.checkPortAvailable(service.Port, 0); err != nil {
+	if err := s.checkPortAvailable(ctx, service.Port, 0); err != nil {
 		return err
 	}
 
 	// 检查服务名是否已存在
-	if err := s.checkNameAvailable(service.Name, 0); err != nil {
+	if err := s.checkNameAvailable(ctx, service.Name, 0); err != nil {
 		return err
 	}
 
-	// 创建服务
-	if err := s.db.WithContext(ctx).Create(service).Error; err != nil {
-		return common.WrapError(common.ErrCodeDatabaseError, "创建服务失败", err)
+	// 创建服务并记录操作日志和版本
+	output := fmt.Sprintf("添加服务 %s，端口 %d，目录 %s", service.Name, service.Port, service.Dir)
+	change := newServiceChange(ctx, "create", output, model.RevisionActionCreate, nil, nil, "")
+	if err := s.services.Create(ctx, service, change); err != nil {
+		return s.writeError(ctx, service, "创建服务失败", err)
 	}
 
 	return nil
@@ -67,46 +83,107 @@ func (s *ServiceService) UpdateService(ctx context.Context, service *model.Servi
 	}
 
 	// 检查服务是否存在
-	var existing model.ServiceModel
-	if err := s.db.WithContext(ctx).First(&existing, service.Id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return common.ErrServiceNotFound
-		}
-		return common.WrapError(common.ErrCodeDatabaseError, "查询服务失败", err)
+	existing, err := s.getService(ctx, service.Id)
+	if err != nil {
+		return err
 	}
 
 	// 检查端口是否被其他服务占用
-	if err := s.checkPortAvailable(service.Port, service.Id); err != nil {
+	if err := s.checkPortAvailable(ctx, service.Port, service.Id); err != nil {
 		return err
 	}
 
 	// 检查服务名是否被其他服务占用
-	if err := s.checkNameAvailable(service.Name, service.Id); err != nil {
+	if err := s.checkNameAvailable(ctx, service.Name, service.Id); err != nil {
 		return err
 	}
 
-	// 更新服务
-	if err := s.db.WithContext(ctx).Model(&existing).Updates(service).Error; err != nil {
-		return common.WrapError(common.ErrCodeDatabaseError, "更新服务失败", err)
+	// 只更新请求中的非零值字段，记录修改的字段并保存新版本
+	updated := mergeServiceUpdate(existing, service)
+	changes := model.DiffServices(existing, &updated)
+	change := newServiceChange(ctx, "update", formatServiceChanges(changes), model.RevisionActionUpdate, changes, existing, "")
+	if err := s.services.Update(ctx, &updated, change); err != nil {
+		return s.writeError(ctx, &updated, "更新服务失败", err)
 	}
 
 	return nil
 }
 
+// RestoreService 使用 restored 替换服务配置并保存为恢复版本，服务已删除时按原ID重新添加
+// deleted 为服务删除时的配置，服务已删除时用于计算变化的字段
+func (s *ServiceService) RestoreService(ctx context.Context, restored *model.ServiceModel, deleted *model.ServiceModel, comment string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := restored.Validate(); err != nil {
+		return common.WrapError(common.ErrCodeInvalidParam, "服务数据验证失败", err)
+	}
+	if err := s.checkPortAvailable(ctx, restored.Port, restored.Id); err != nil {
+		return err
+	}
+	if err := s.checkNameAvailable(ctx, restored.Name, restored.Id); err != nil {
+		return err
+	}
+
+	previous, err := s.services.Get(ctx, restored.Id)
+	exists := err == nil
+	if err == repository.ErrNotFound {
+		previous = &model.ServiceModel{}
+		if deleted != nil {
+			previous = deleted
+		}
+	} else if err != nil {
+		return common.WrapError(common.ErrCodeDatabaseError, "查询服务失败", err)
+	}
+
+	changes := model.DiffServices(previous, restored)
+	output := comment + "\n" + formatServiceChanges(changes)
+	change := newServiceChange(ctx, "restore", output, model.RevisionActionRestore, changes, previous, comment)
+	if exists {
+		err = s.services.Update(ctx, restored, change)
+	} else {
+		err = s.services.Create(ctx, restored, change)
+	}
+	if err != nil {
+		return s.writeError(ctx, restored, "恢复服务配置失败", err)
+	}
+	return nil
+}
+
+// writeError 保存服务失败时的错误，并发添加或修改造成名称、端口重复时返回与写入前检查相同的冲突错误
+func (s *ServiceService) writeError(ctx context.Context, service *model.ServiceModel, message string, err error) error {
+	if !errors.Is(err, repository.ErrDuplicate) {
+		return common.WrapError(common.ErrCodeDatabaseError, message, err)
+	}
+	if conflict := s.checkPortAvailable(ctx, service.Port, service.Id); conflict != nil {
+		return conflict
+	}
+	if conflict := s.checkNameAvailable(ctx, service.Name, service.Id); conflict != nil {
+		return conflict
+	}
+	// ID重复：已删除的服务已被恢复
+	return common.NewBusinessError(common.ErrCodeInvalidParam, fmt.Sprintf("服务 %d 已存在", service.Id))
+}
+
 // GetServiceById 根据ID获取服务
 func (s *ServiceService) GetServiceById(ctx context.Context, id int64) (*model.ServiceModel, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	var service model.ServiceModel
-	if err := s.db.WithContext(ctx).First(&service, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	return s.getService(ctx, id)
+}
+
+// getService 根据ID获取服务，不加锁
+func (s *ServiceService) getService(ctx context.Context, id int64) (*model.ServiceModel, error) {
+	service, err := s.services.Get(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
 			return nil, common.ErrServiceNotFound
 		}
 		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务失败", err)
 	}
 
-	return &service, nil
+	return service, nil
 }
 
 // GetServiceByName 根据名称获取服务
@@ -114,15 +191,15 @@ func (s *ServiceService) GetServiceByName(ctx context.Context, name string) (*mo
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	var service model.ServiceModel
-	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&service).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	service, err := s.services.FindByName(ctx, name, 0)
+	if err != nil {
+		if err == repository.ErrNotFound {
 			return nil, common.ErrServiceNotFound
 		}
 		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务失败", err)
 	}
 
-	return &service, nil
+	return service, nil
 }
 
 // DeleteService 删除服务
@@ -130,26 +207,81 @@ func (s *ServiceService) DeleteService(ctx context.Context, id int64) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// 检查服务是否存在
-	service, err := s.GetServiceById(ctx, id)
+	// 检查服务是否存在(已持有写锁，不能调用 GetServiceById)
+	service, err := s.getService(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// 检查服务是否正在运行
-	port := strconv.Itoa(int(service.Port))
-	if isRunning, _ := utils.IsPortInUse(port); isRunning {
+	if isServiceRunning(service) {
 		return common.NewBusinessError(common.ErrCodeServiceRunning, "无法删除正在运行的服务，请先停止服务")
 	}
 
-	// 删除服务
-	if err := s.db.WithContext(ctx).Delete(&model.ServiceModel{}, id).Error; err != nil {
+	// 删除服务及按服务的授权，操作日志保留，删除前的配置保存为新版本
+	output := fmt.Sprintf("删除服务 %s，端口 %d", service.Name, service.Port)
+	change := newServiceChange(ctx, "delete", output, model.RevisionActionDelete, nil, nil, "")
+	if err := s.services.Delete(ctx, service, change); err != nil {
 		return common.WrapError(common.ErrCodeDatabaseError, "删除服务失败", err)
 	}
 
 	return nil
 }
 
+// mergeServiceUpdate 将 update 中的非零值字段合并到 existing 的副本，未填写的字段保持不变
+func mergeServiceUpdate(existing, update *model.ServiceModel) model.ServiceModel {
+	merged := *existing
+	source := reflect.ValueOf(update).Elem()
+	target := reflect.ValueOf(&merged).Elem()
+	for i := 0; i < source.NumField(); i++ {
+		switch source.Type().Field(i).Name {
+		case "Id", "CreatedAt", "UpdatedAt":
+			continue
+		}
+		if field := source.Field(i); !field.IsZero() {
+			target.Field(i).Set(field)
+		}
+	}
+	return merged
+}
+
+// newServiceChange 服务变更的操作日志和版本，changes 为相对 previous 变化的字段
+// previous 不为空时，服务还没有版本则先将其保存为基准版本
+func newServiceChange(ctx context.Context, operation, output, action string, changes []model.ServiceFieldChange, previous *model.ServiceModel, comment string) repository.ServiceChange {
+	source := operationSource(ctx)
+	changed := make([]string, 0, len(changes))
+	for _, change := range changes {
+		changed = append(changed, change.Field)
+	}
+	serviceChange := repository.ServiceChange{
+		Log: newServiceLog(ctx, 0, operation, "success", output, "", 0),
+		Revision: model.ServiceRevisionModel{
+			Action:        action,
+			ChangedFields: changed,
+			Author:        source.Actor,
+			RequestId:     source.RequestId,
+			Comment:       comment,
+		},
+	}
+	if previous != nil {
+		serviceChange.Baseline = &model.ServiceRevisionModel{
+			Action:   model.RevisionActionBaseline,
+			Snapshot: previous,
+			Comment:  "记录版本前的配置",
+		}
+	}
+	return serviceChange
+}
+
+// formatServiceChanges 变化的字段，每行一个，格式为 字段: 原值 -> 新值
+func formatServiceChanges(changes []model.ServiceFieldChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", change.Field, change.Old, change.New))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ListServices 获取服务列表
 func (s *ServiceService) ListServices(ctx context.Context, req *model.ServiceListRequest) (*model.ServiceListResponse, error) {
 	s.mutex.RLock()
@@ -163,23 +295,10 @@ func (s *ServiceService) ListServices(ctx context.Context, req *model.ServiceLis
 		req.PageSize = 20
 	}
 
-	query := s.db.WithContext(ctx).Model(&model.ServiceModel{})
-
-	// 按名称过滤
-	if req.Name != "" {
-		query = query.Where("name LIKE ?", "%"+req.Name+"%")
-	}
-
-	// 获取总数
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务总数失败", err)
-	}
-
-	// 获取服务列表
-	var services []model.ServiceModel
+	// 获取服务列表和总数
 	offset := (req.Page - 1) * req.PageSize
-	if err := query.Offset(offset).Limit(req.PageSize).Find(&services).Error; err != nil {
+	services, total, err := s.services.ListPage(ctx, req, offset, req.PageSize)
+	if err != nil {
 		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务列表失败", err)
 	}
 
@@ -212,11 +331,16 @@ func (s *ServiceService) ListServices(ctx context.Context, req *model.ServiceLis
 
 // GetAllServicesWithStatus 获取所有服务及其状态
 func (s *ServiceService) GetAllServicesWithStatus(ctx context.Context) ([]model.ServiceStatusModel, error) {
+	return s.FindServicesWithStatus(ctx, &model.ServiceListRequest{})
+}
+
+// FindServicesWithStatus 按名称、分组和权限范围查询服务及其状态，不分页
+func (s *ServiceService) FindServicesWithStatus(ctx context.Context, req *model.ServiceListRequest) ([]model.ServiceStatusModel, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	var services []model.ServiceModel
-	if err := s.db.WithContext(ctx).Find(&services).Error; err != nil {
+	services, err := s.services.List(ctx, req)
+	if err != nil {
 		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询服务列表失败", err)
 	}
 
@@ -240,18 +364,55 @@ func (s *ServiceService) GetAllServicesWithStatus(ctx context.Context) ([]model.
 	return serviceStatuses, nil
 }
 
-// checkPortAvailable 检查端口是否可用
-func (s *ServiceService) checkPortAvailable(port int64, excludeId int64) error {
-	var existing model.ServiceModel
-	query := s.db.Where("port = ?", port)
-	if excludeId > 0 {
-		query = query.Where("id != ?", excludeId)
+// GetAutoRestartServices 获取开启自动重启的服务
+func (s *ServiceService) GetAutoRestartServices(ctx context.Context) ([]model.ServiceModel, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	services, err := s.services.ListAutoRestart(ctx)
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询自动重启服务失败", err)
 	}
-	
-	if err := query.First(&existing).Error; err == nil {
+
+	return services, nil
+}
+
+// GetHealthCheckServices 获取配置了健康检查地址或存活探针的服务
+func (s *ServiceService) GetHealthCheckServices(ctx context.Context) ([]model.ServiceModel, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	services, err := s.services.ListHealthCheck(ctx)
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询健康检查服务失败", err)
+	}
+
+	return services, nil
+}
+
+// GetServiceStatusById 根据ID获取服务及其运行状态
+func (s *ServiceService) GetServiceStatusById(ctx context.Context, id int64) (*model.ServiceStatusModel, error) {
+	service, err := s.GetServiceById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	portList, err := utils.GetPortList()
+	if err != nil {
+		return nil, common.WrapError(common.ErrCodeCommandFailed, "获取端口状态失败", err)
+	}
+
+	status := s.buildServiceStatus(*service, portList)
+	return &status, nil
+}
+
+// checkPortAvailable 检查端口是否可用
+func (s *ServiceService) checkPortAvailable(ctx context.Context, port int64, excludeId int64) error {
+	existing, err := s.services.FindByPort(ctx, port, excludeId)
+	if err == nil {
 		return common.NewBusinessError(common.ErrCodePortInUse, 
 			fmt.Sprintf("端口 %d 已被服务 '%s' 占用", port, existing.Name))
-	} else if err != gorm.ErrRecordNotFound {
+	} else if err != repository.ErrNotFound {
 		return common.WrapError(common.ErrCodeDatabaseError, "检查端口占用失败", err)
 	}
 	
@@ -259,17 +420,11 @@ func (s *ServiceService) checkPortAvailable(port int64, excludeId int64) error {
 }
 
 // checkNameAvailable 检查服务名是否可用
-func (s *ServiceService) checkNameAvailable(name string, excludeId int64) error {
-	var existing model.ServiceModel
-	query := s.db.Where("name = ?", name)
-	if excludeId > 0 {
-		query = query.Where("id != ?", excludeId)
-	}
-	
-	if err := query.First(&existing).Error; err == nil {
+func (s *ServiceService) checkNameAvailable(ctx context.Context, name string, excludeId int64) error {
+	if _, err := s.services.FindByName(ctx, name, excludeId); err == nil {
 		return common.NewBusinessError(common.ErrCodeInvalidParam, 
 			fmt.Sprintf("服务名称 '%s' 已存在", name))
-	} else if err != gorm.ErrRecordNotFound {
+	} else if err != repository.ErrNotFound {
 		return common.WrapError(common.ErrCodeDatabaseError, "检查服务名称失败", err)
 	}
 	
@@ -277,7 +432,7 @@ func (s *ServiceService) checkNameAvailable(name string, excludeId int64) error
 }
 
 // buildServiceStatus 构建服务状态信息
-func (s *ServiceService) buildServiceStatus(service model.ServiceModel, portList map[string]map[string]interface{}) model.ServiceStatusModel {
+func (s *ServiceService) buildServiceStatus(service model.ServiceModel, portList map[int64]utils.ListenerInfo) model.ServiceStatusModel {
 	status := model.ServiceStatusModel{
 		ServiceModel: service,
 		Status:       0, // 默认停止状态
@@ -285,36 +440,51 @@ func (s *ServiceService) buildServiceStatus(service model.ServiceModel, portList
 		Process:      "",
 	}
 
-	port := strconv.Itoa(int(service.Port))
-	if portInfo, exists := portList[port]; exists {
+	if listener, exists := portList[service.Port]; exists {
 		status.Status = 1 // 运行状态
-		if pid, ok := portInfo["pid"].(string); ok {
-			status.Pid = pid
+		if listener.Pid > 0 {
+			status.Pid = strconv.Itoa(listener.Pid)
+		}
+		status.Process = listener.Process
+	}
+
+	// 托管进程以进程状态为准，端口仅作为辅助判断
+	if process := getManagedProcess(service.Id); process != nil {
+		status.ManagedProcess = process.info()
+		if !status.ManagedProcess.Exited {
+			status.Status = 1
+			status.Pid = strconv.Itoa(status.ManagedProcess.Pid)
+			if status.Process == "" {
+				status.Process = filepath.Base(process.cmd.Path)
+			}
 		}
-		if process, ok := portInfo["process"].(string); ok {
-			status.Process = process
+	}
+
+	// 附加健康检查结果，检查失败时标记为不健康
+	if status.Status == 1 {
+		if health := getHealthResult(service.Id); health != nil {
+			status.Health = health
+			if !health.Healthy {
+				status.Status = 2
+			}
 		}
 	}
 
 	return status
 }
 
-// HealthCheck 健康检查
+// HealthCheck 健康检查，不持有读锁：GetAllServicesWithStatus 内部会加读锁，重复加读锁在有写锁等待时会死锁
 func (s *ServiceService) HealthCheck(ctx context.Context) map[string]interface{} {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	var total int64
 	var running int64
 
 	// 获取服务总数
-	s.db.WithContext(ctx).Model(&model.ServiceModel{}).Count(&total)
+	total, _ := s.services.Count(ctx)
 
 	// 获取运行中的服务数量
 	services, err := s.GetAllServicesWithStatus(ctx)
 	if err == nil {
 		for _, service := range services {
-			if service.Status == 1 {
+			if service.Status != 0 {
 				running++
 			}
 		}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go_service/app/common"
+	"go_service/app/model"
+	"go_service/app/repository"
+	"net"
+	"os"
+	"testing"
+)
+
+// newTestServiceService 使用内存存储的服务管理
+func newTestServiceService() (*ServiceService, *repository.MemoryServiceRepository, *repository.MemoryLogRepository) {
+	services, logs := repository.NewMemoryRepositories()
+	return NewServiceServiceWithRepository(services), services, logs
+}
+
+func testService(name string, port int64) *model.ServiceModel {
+	return &model.ServiceModel{
+		Name:     name,
+		Title:    name,
+		Dir:      "/tmp",
+		CmdStart: "./" + name,
+		Port:     port,
+		Group:    "default",
+	}
+}
+
+// mustCreate 添加服务，失败时终止测试
+func mustCreate(t *testing.T, s *ServiceService, service *model.ServiceModel) *model.ServiceModel {
+	t.Helper()
+	if err := s.CreateService(context.Background(), service); err != nil {
+		t.Fatalf("添加服务 %s 失败: %v", service.Name, err)
+	}
+	return service
+}
+
+// errorCode 业务错误的错误码，不是业务错误时为 0
+func errorCode(err error) int {
+	var businessError *common.BusinessError
+	if errors.As(err, &businessError) {
+		return businessError.Code
+	}
+	return 0
+}
+
+func TestCreateServiceConflicts(t *testing.T) {
+	s, services, logs := newTestServiceService()
+	ctx := context.Background()
+	existing := mustCreate(t, s, testService("api", 18080))
+	if existing.Id <= 0 {
+		t.Fatalf("添加后的服务没有ID")
+	}
+
+	tests := []struct {
+		name    string
+		service *model.ServiceModel
+		code    int
+	}{
+		{"端口被占用", testService("web", 18080), common.ErrCodePortInUse},
+		{"名称已存在", testService("api", 18081), common.ErrCodeInvalidParam},
+		{"缺少启动命令", &model.ServiceModel{Name: "job", Dir: "/tmp", Port: 18082}, common.ErrCodeInvalidParam},
+		{"端口超出范围", testService("job", 70000), common.ErrCodeInvalidParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.CreateService(ctx, tt.service)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("错误码为 %d(%v)，期望 %d", code, err, tt.code)
+			}
+		})
+	}
+
+	if total, _ := services.Count(ctx); total != 1 {
+		t.Fatalf("服务数为 %d，冲突的服务不应添加", total)
+	}
+	entries, _ := logs.ListByService(ctx, existing.Id, 0)
+	if len(entries) != 1 || entries[0].Operation != "create" {
+		t.Fatalf("添加服务应记录一条 create 日志，实际为 %+v", entries)
+	}
+	revisions := services.Revisions(existing.Id)
+	if len(revisions) != 1 || revisions[0].Action != model.RevisionActionCreate || revisions[0].Snapshot.Port != 18080 {
+		t.Fatalf("添加服务应保存 create 版本，实际为 %+v", revisions)
+	}
+}
+
+func TestUpdateService(t *testing.T) {
+	s, services, logs := newTestServiceService()
+	ctx := context.Background()
+	api := mustCreate(t, s, testService("api", 18080))
+	mustCreate(t, s, testService("web", 18081))
+
+	// 未填写的字段保持不变
+	update := &model.ServiceModel{Id: api.Id, Name: "api", Title: "接口服务", Dir: "/tmp", CmdStart: "./api --debug", Port: 18080}
+	if err := s.UpdateService(ctx, update); err != nil {
+		t.Fatalf("更新服务失败: %v", err)
+	}
+	updated, err := s.GetServiceById(ctx, api.Id)
+	if err != nil {
+		t.Fatalf("获取服务失败: %v", err)
+	}
+	if updated.Title != "接口服务" || updated.CmdStart != "./api --debug" {
+		t.Fatalf("更新的字段未保存: %+v", updated)
+	}
+	if updated.Group != "default" {
+		t.Fatalf("未填写的分组被修改为 %q", updated.Group)
+	}
+
+	entries, _ := logs.ListByService(ctx, api.Id, 1)
+	if len(entries) != 1 || entries[0].Operation != "update" {
+		t.Fatalf("更新服务应记录 update 日志，实际为 %+v", entries)
+	}
+	revisions := services.Revisions(api.Id)
+	latest := revisions[len(revisions)-1]
+	if latest.Revision != 2 || latest.Action != model.RevisionActionUpdate {
+		t.Fatalf("更新服务应保存版本 2，实际为 %+v", latest)
+	}
+	if len(latest.ChangedFields) != 2 || latest.ChangedFields[0] != "cmd_start" || latest.ChangedFields[1] != "title" {
+		t.Fatalf("变化的字段为 %v，期望 [cmd_start title]", latest.ChangedFields)
+	}
+
+	// 端口和名称不能与其他服务重复，服务不存在时返回服务不存在
+	conflicts := []struct {
+		name    string
+		service *model.ServiceModel
+		code    int
+	}{
+		{"端口被占用", &model.ServiceModel{Id: api.Id, Name: "api", Dir: "/tmp", CmdStart: "./api", Port: 18081}, common.ErrCodePortInUse},
+		{"名称已存在", &model.ServiceModel{Id: api.Id, Name: "web", Dir: "/tmp", CmdStart: "./api", Port: 18080}, common.ErrCodeInvalidParam},
+		{"服务不存在", &model.ServiceModel{Id: 999, Name: "job", Dir: "/tmp", CmdStart: "./job", Port: 18082}, common.ErrCodeServiceNotFound},
+		{"无效的ID", &model.ServiceModel{Name: "job", Dir: "/tmp", CmdStart: "./job", Port: 18082}, common.ErrCodeInvalidParam},
+	}
+	for _, tt := range conflicts {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.UpdateService(ctx, tt.service)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("错误码为 %d(%v)，期望 %d", code, err, tt.code)
+			}
+		})
+	}
+	if current, _ := s.GetServiceById(ctx, api.Id); current.Port != 18080 || current.Name != "api" {
+		t.Fatalf("更新失败时服务被修改: %+v", current)
+	}
+}
+
+func TestDeleteServiceWhileRunning(t *testing.T) {
+	s, services, logs := newTestServiceService()
+	ctx := context.Background()
+
+	// 监听一个端口模拟正在运行的服务
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	defer listener.Close()
+	port := int64(listener.Addr().(*net.TCPAddr).Port)
+	service := mustCreate(t, s, testService("api", port))
+
+	if err := s.DeleteService(ctx, service.Id); errorCode(err) != common.ErrCodeServiceRunning {
+		t.Fatalf("删除正在运行的服务应失败，实际为 %v", err)
+	}
+	if _, err := s.GetServiceById(ctx, service.Id); err != nil {
+		t.Fatalf("删除失败后服务应保留: %v", err)
+	}
+
+	listener.Close()
+	if err := s.DeleteService(ctx, service.Id); err != nil {
+		t.Fatalf("删除已停止的服务失败: %v", err)
+	}
+	if _, err := s.GetServiceById(ctx, service.Id); err != common.ErrServiceNotFound {
+		t.Fatalf("删除后获取服务应返回服务不存在，实际为 %v", err)
+	}
+	if err := s.DeleteService(ctx, service.Id); err != common.ErrServiceNotFound {
+		t.Fatalf("重复删除应返回服务不存在，实际为 %v", err)
+	}
+
+	// 操作日志和版本保留，删除版本保存删除前的配置
+	entries, _ := logs.ListByService(ctx, service.Id, 0)
+	if len(entries) != 2 || entries[0].Operation != "delete" {
+		t.Fatalf("删除服务应记录 delete 日志，实际为 %+v", entries)
+	}
+	revisions := services.Revisions(service.Id)
+	latest := revisions[len(revisions)-1]
+	if latest.Action != model.RevisionActionDelete || latest.Snapshot == nil || latest.Snapshot.Port != port {
+		t.Fatalf("删除服务应保存删除前的配置，实际为 %+v", latest)
+	}
+}
+
+func TestListServicesFiltering(t *testing.T) {
+	if _, err := os.Stat("/proc/net/tcp"); err != nil {
+		t.Skip("查询端口状态需要 /proc/net/tcp")
+	}
+	s, _, _ := newTestServiceService()
+	ctx := context.Background()
+	fixtures := []struct {
+		name  string
+		group string
+	}{
+		{"order-api", "order"},
+		{"order-worker", "order"},
+		{"user-api", "user"},
+		{"report", "report"},
+	}
+	ids := map[string]int64{}
+	for i, fixture := range fixtures {
+		service := testService(fixture.name, int64(18180+i))
+		service.Group = fixture.group
+		ids[fixture.name] = mustCreate(t, s, service).Id
+	}
+
+	tests := []struct {
+		name  string
+		req   model.ServiceListRequest
+		total int64
+		want  []string
+	}{
+		{"全部", model.ServiceListRequest{}, 4, []string{"order-api", "order-worker", "user-api", "report"}},
+		{"按名称", model.ServiceListRequest{Name: "API"}, 2, []string{"order-api", "user-api"}},
+		{"按分组", model.ServiceListRequest{Group: "order"}, 2, []string{"order-api", "order-worker"}},
+		{"名称和分组", model.ServiceListRequest{Name: "api", Group: "order"}, 1, []string{"order-api"}},
+		{"分页", model.ServiceListRequest{Page: 2, PageSize: 3}, 4, []string{"report"}},
+		{"权限范围", model.ServiceListRequest{Scope: &model.ServiceScope{ServiceIds: []int64{ids["report"]}, Groups: []string{"user"}}}, 2, []string{"user-api", "report"}},
+		{"无权限", model.ServiceListRequest{Scope: &model.ServiceScope{}}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			result, err := s.ListServices(ctx, &req)
+			if err != nil {
+				t.Fatalf("查询服务列表失败: %v", err)
+			}
+			if result.Total != tt.total {
+				t.Fatalf("总数为 %d，期望 %d", result.Total, tt.total)
+			}
+			var names []string
+			for _, service := range result.List {
+				names = append(names, service.Name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("返回 %v，期望 %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Fatalf("返回 %v，期望 %v", names, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreService(t *testing.T) {
+	s, services, _ := newTestServiceService()
+	ctx := context.Background()
+	api := mustCreate(t, s, testService("api", 18280))
+	deleted := *api
+	if err := s.DeleteService(ctx, api.Id); err != nil {
+		t.Fatalf("删除服务失败: %v", err)
+	}
+	mustCreate(t, s, testService("web", 18280))
+
+	// 删除后端口被其他服务占用，恢复应返回与添加服务相同的冲突错误
+	restored := deleted
+	if err := s.RestoreService(ctx, &restored, &deleted, "恢复到版本 1"); errorCode(err) != common.ErrCodePortInUse {
+		t.Fatalf("端口被占用时恢复应失败，实际为 %v", err)
+	}
+
+	restored.Port = 18281
+	if err := s.RestoreService(ctx, &restored, &deleted, "恢复到版本 1"); err != nil {
+		t.Fatalf("恢复服务失败: %v", err)
+	}
+	current, err := s.GetServiceById(ctx, api.Id)
+	if err != nil || current.Port != 18281 {
+		t.Fatalf("恢复后的服务为 %+v(%v)，期望按原ID添加", current, err)
+	}
+	revisions := services.Revisions(api.Id)
+	latest := revisions[len(revisions)-1]
+	if latest.Action != model.RevisionActionRestore || len(latest.ChangedFields) != 1 || latest.ChangedFields[0] != "port" {
+		t.Fatalf("恢复应保存 restore 版本并记录变化的字段，实际为 %+v", latest)
+	}
+}
+
+func TestWriteErrorConflicts(t *testing.T) {
+	s, _, _ := newTestServiceService()
+	ctx := context.Background()
+	api := mustCreate(t, s, testService("api", 18380))
+
+	// 写入前检查之后并发写入造成的唯一键冲突，返回与检查相同的错误
+	tests := []struct {
+		name    string
+		service *model.ServiceModel
+		err     error
+		code    int
+	}{
+		{"端口重复", testService("web", 18380), repository.ErrDuplicate, common.ErrCodePortInUse},
+		{"名称重复", testService("api", 18381), repository.ErrDuplicate, common.ErrCodeInvalidParam},
+		{"ID重复", &model.ServiceModel{Id: api.Id + 1, Name: "job", Port: 18382}, repository.ErrDuplicate, common.ErrCodeInvalidParam},
+		{"其他错误", testService("job", 18382), errors.New("连接已断开"), common.ErrCodeDatabaseError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.writeError(ctx, tt.service, "保存服务失败", tt.err)
+			if code := errorCode(err); code != tt.code {
+				t.Fatalf("错误码为 %d(%v)，期望 %d", code, err, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/common"
+	"go_service/app/model"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type UserService struct {
+	db *gorm.DB
+}
+
+func NewUserService(db *gorm.DB) *UserService {
+	return &UserService{
+		db: db,
+	}
+}
+
+// ListUsers 获取所有用户
+func (u *UserService) ListUsers(ctx context.Context) ([]model.UserModel, error) {
+	users := []model.UserModel{}
+	if err := u.db.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	return users, nil
+}
+
+// CreateUser 创建用户
+func (u *UserService) CreateUser(ctx context.Context, req *model.UserRequest) (*model.UserModel, error) {
+	if req.Username == "" {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "用户名不能为空")
+	}
+	if err := model.ValidateRole(req.Role, true); err != nil {
+		return nil, common.WrapError(common.ErrCodeInvalidParam, "角色验证失败", err)
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := u.db.WithContext(ctx).Model(&model.UserModel{}).Where("username = ?", req.Username).Count(&count).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	if count > 0 {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "用户名已存在")
+	}
+
+	user := &model.UserModel{
+		Username: req.Username,
+		Password: hash,
+		Role:     req.Role,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if err := u.db.WithContext(ctx).Create(user).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "创建用户失败", err)
+	}
+	return user, nil
+}
+
+// UpdateUser 修改用户的角色、启用状态或密码，修改密码或禁用时使已签发的令牌失效
+// operatorId 为当前操作的用户，不能修改自己的角色或禁用自己
+func (u *UserService) UpdateUser(ctx context.Context, req *model.UserRequest, operatorId int64) (*model.UserModel, error) {
+	var user model.UserModel
+	if err := u.db.WithContext(ctx).First(&user, req.Id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "用户不存在")
+		}
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	if err := model.ValidateRole(req.Role, true); err != nil {
+		return nil, common.WrapError(common.ErrCodeInvalidParam, "角色验证失败", err)
+	}
+
+	enabled := user.Enabled
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	if user.Id == operatorId && (req.Role != user.Role || !enabled) {
+		return nil, common.NewBusinessError(common.ErrCodeInvalidParam, "不能修改自己的角色或禁用自己")
+	}
+
+	updates := map[string]interface{}{
+		"role":    req.Role,
+		"enabled": enabled,
+	}
+	revoke := user.Enabled && !enabled
+	if req.Password != "" {
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		updates["password"] = hash
+		revoke = true
+	}
+	if revoke {
+		updates["token_version"] = gorm.Expr("token_version + 1")
+	}
+
+	if err := u.db.WithContext(ctx).Model(&user).Updates(updates).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "更新用户失败", err)
+	}
+	if err := u.db.WithContext(ctx).First(&user, req.Id).Error; err != nil {
+		return nil, common.WrapError(common.ErrCodeDatabaseError, "查询用户失败", err)
+	}
+	return &user, nil
+}
+
+// DeleteUser 删除用户及其授权，operatorId 为当前操作的用户，不能删除自己
+func (u *UserService) DeleteUser(ctx context.Context, id, operatorId int64) error {
+	if id == operatorId {
+		return common.NewBusinessError(common.ErrCodeInvalidParam, "不能删除自己")
+	}
+
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.UserModel{}, id)
+		if result.Error != nil {
+			return common.WrapError(common.ErrCodeDatabaseError, "删除用户失败", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return common.NewBusinessError(common.ErrCodeInvalidParam, "用户不存在")
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.UserPermissionModel{}).Error; err != nil {
+			return common.WrapError(common.ErrCodeDatabaseError, "删除用户授权失败", err)
+		}
+		return nil
+	})
+}
+
+// hashPassword 校验密码强度并生成 bcrypt 哈希
+func hashPassword(password string) (string, error) {
+	if err := model.ValidatePassword(password); err != nil {
+		return "", common.WrapError(common.ErrCodeInvalidParam, "密码验证失败", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("密码加密失败: %v", err)
+	}
+	return string(hash), nil
+}
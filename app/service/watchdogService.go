@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go_service/app/model"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// watchdogState 单个服务的守护状态
+type watchdogState struct {
+	observed     bool      // 是否已完成首次检测
+	running      bool      // 上次检测时是否运行
+	runningSince time.Time // 本次连续运行的开始时间
+	pending      bool      // 检测到异常退出，等待自动重启
+	restartCount int       // 连续自动重启次数
+	lastRestart  time.Time // 上次自动重启时间
+	gaveUp       bool      // 已超过最大重启次数
+}
+
+// WatchdogService 自动重启守护服务
+// 周期性检查开启了 AutoRestart 的服务，发现非人为停止时按 RestartInterval 和 MaxRestartCount 自动拉起
+type WatchdogService struct {
+	serviceService *ServiceService
+	commandService *CommandService
+	logService     *LogService
+	interval       time.Duration
+	stopChannel    chan struct{}
+	wg             sync.WaitGroup
+
+	// 连续健康检查失败达到该次数视为故障，<= 0 表示不根据健康检查重启
+	unhealthyThreshold int
+
+	mutex  sync.Mutex
+	states map[int64]*watchdogState
+}
+
+const (
+	// watchdogOperationGrace 操作结束后的保护时间，避免把重启过程中的短暂停止误判为异常退出
+	watchdogOperationGrace = 10 * time.Second
+	// watchdogResetWindow 服务稳定运行超过该时间后重置重启计数
+	watchdogResetWindow = 10 * time.Minute
+)
+
+func NewWatchdogService(db *gorm.DB, interval time.Duration) *WatchdogService {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	commandService := NewCommandService(db)
+	return &WatchdogService{
+		serviceService:     commandService.serviceService,
+		commandService:     commandService,
+		logService:         commandService.logService,
+		interval:           interval,
+		stopChannel:        make(chan struct{}),
+		unhealthyThreshold: 3,
+		states:             make(map[int64]*watchdogState),
+	}
+}
+
+// SetUnhealthyThreshold 设置触发重启的连续健康检查失败次数
+func (w *WatchdogService) SetUnhealthyThreshold(threshold int) {
+	w.unhealthyThreshold = threshold
+}
+
+// Start 启动守护协程
+func (w *WatchdogService) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.check()
+			case <-w.stopChannel:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止守护协程
+func (w *WatchdogService) Stop() {
+	close(w.stopChannel)
+	w.wg.Wait()
+}
+
+// check 执行一轮检测
+func (w *WatchdogService) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	services, err := w.serviceService.GetAutoRestartServices(ctx)
+	if err != nil {
+		log.Printf("自动重启守护查询服务失败: %v", err)
+		return
+	}
+
+	active := make(map[int64]bool, len(services))
+	for _, service := range services {
+		active[service.Id] = true
+		w.checkService(service)
+	}
+
+	// 清理已关闭自动重启或已删除的服务状态
+	w.mutex.Lock()
+	for id := range w.states {
+		if !active[id] {
+			delete(w.states, id)
+		}
+	}
+	w.mutex.Unlock()
+}
+
+// checkService 检测单个服务并在需要时自动重启
+func (w *WatchdogService) checkService(service model.ServiceModel) {
+	running := isServiceRunning(&service)
+	now := time.Now()
+
+	w.mutex.Lock()
+	state, ok := w.states[service.Id]
+	if !ok {
+		state = &watchdogState{}
+		w.states[service.Id] = state
+	}
+
+	// 存活探针使用自身的失败阈值，否则使用全局阈值
+	unhealthy := false
+	if health := getHealthResult(service.Id); running && health != nil {
+		threshold := w.unhealthyThreshold
+		if health.FailureThreshold > 0 {
+			threshold = health.FailureThreshold
+		}
+		unhealthy = threshold > 0 && health.ConsecutiveFailures >= threshold
+	}
+
+	if running && !unhealthy {
+		// 主动停止后在 go_service 之外重新启动的服务恢复自动重启，停止操作进行中时服务仍在运行，保留标记
+		if isManualStopped(service.Id) && !isOperating(service.Id, watchdogOperationGrace) {
+			clearManualStop(service.Id)
+			log.Printf("服务 %s(%d) 已在外部重新启动，恢复自动重启", service.Name, service.Id)
+		}
+		if !state.running {
+			state.runningSince = now
+		}
+		state.observed = true
+		state.running = true
+		state.pending = false
+		state.gaveUp = false
+		if state.restartCount > 0 && now.Sub(state.runningSince) > watchdogResetWindow {
+			state.restartCount = 0
+		}
+		w.mutex.Unlock()
+		return
+	}
+
+	// 操作进行中或刚结束，保留上次状态等待下一轮检测
+	if isOperating(service.Id, watchdogOperationGrace) {
+		w.mutex.Unlock()
+		return
+	}
+
+	wasRunning := state.observed && state.running
+	state.observed = true
+	state.running = running
+
+	if unhealthy {
+		// 进程仍在但连续健康检查失败，视为故障
+		if !state.pending {
+			state.pending = true
+			log.Printf("服务 %s(%d) 连续多次健康检查失败", service.Name, service.Id)
+		}
+	} else {
+		// 人为停止的服务不做处理
+		if isManualStopped(service.Id) {
+			state.pending = false
+			w.mutex.Unlock()
+			return
+		}
+
+		if wasRunning {
+			state.pending = true
+			// 托管进程退出时已发送告警，这里只处理非托管的服务
+			if process := getManagedProcess(service.Id); process != nil {
+				log.Printf("检测到服务 %s(%d) 异常停止: %s", service.Name, service.Id, process.exitDescription())
+			} else {
+				log.Printf("检测到服务 %s(%d) 异常停止", service.Name, service.Id)
+				sendAlert(serviceAlert(&service, model.AlertServiceDown, model.AlertLevelCritical, "进程已退出"))
+			}
+		}
+	}
+	if !state.pending || state.gaveUp {
+		w.mutex.Unlock()
+		return
+	}
+
+	// 超过最大重启次数后不再重启，MaxRestartCount <= 0 表示不限制
+	if service.MaxRestartCount > 0 && state.restartCount >= service.MaxRestartCount {
+		state.gaveUp = true
+		w.mutex.Unlock()
+		msg := fmt.Sprintf("已达到最大重启次数 %d，停止自动重启", service.MaxRestartCount)
+		log.Printf("服务 %s(%d) %s", service.Name, service.Id, msg)
+		w.logService.LogOperation(withTrigger(context.Background(), model.TriggerWatchdog), service.Id, "auto_restart", "failed", "", msg, 0)
+		sendAlert(serviceAlert(&service, model.AlertRestartExhausted, model.AlertLevelCritical, msg))
+		return
+	}
+
+	interval := time.Duration(service.RestartInterval) * time.Second
+	if !state.lastRestart.IsZero() && now.Sub(state.lastRestart) < interval {
+		w.mutex.Unlock()
+		return
+	}
+
+	state.restartCount++
+	state.lastRestart = now
+	attempt := state.restartCount
+	w.mutex.Unlock()
+
+	// 在单独的协程中重启，避免一个服务重启缓慢拖慢其他服务的检测；
+	// 重启期间服务处于操作中，后续检测在上面跳过该服务
+	end, err := beginOperation(service.Id)
+	if err != nil {
+		return
+	}
+	go func() {
+		defer end()
+		w.restart(service, attempt, unhealthy)
+	}()
+}
+
+// restart 通过 CommandService 拉起服务并记录日志，服务仍在运行(不健康)时强制重启
+func (w *WatchdogService) restart(service model.ServiceModel, attempt int, force bool) {
+	// 自动重启失败时发送 restart_failed 告警，不再重复发送启动失败告警
+	ctx, cancel := context.WithTimeout(withoutOperationAlert(withTrigger(context.Background(), model.TriggerWatchdog)), 60*time.Second)
+	defer cancel()
+
+	startTime := time.Now()
+	log.Printf("自动重启服务 %s(%d)，第 %d 次", service.Name, service.Id, attempt)
+
+	var output string
+	var err error
+	if force {
+		output, err = w.commandService.forceRestartService(ctx, service.Id)
+		clearHealthResult(service.Id)
+	} else {
+		output, err = w.commandService.StartService(ctx, service.Id)
+	}
+	output = fmt.Sprintf("第 %d 次自动重启\n%s", attempt, output)
+	if err != nil {
+		log.Printf("自动重启服务 %s(%d) 失败: %v", service.Name, service.Id, err)
+		w.logService.LogOperation(ctx, service.Id, "auto_restart", "failed", output, err.Error(), time.Since(startTime))
+		sendAlert(serviceAlert(&service, model.AlertRestartFailed, model.AlertLevelCritical, fmt.Sprintf("第 %d 次自动重启失败: %v", attempt, err)))
+		return
+	}
+
+	w.logService.LogOperation(ctx, service.Id, "auto_restart", "success", output, "", time.Since(startTime))
+	alertRecovered(service.Id, "auto_restart")
+}
@@ -2,8 +2,20 @@ package main
 
 import (
 	"go_service/app"
+	"os"
 )
 
 func main() {
+	// 子命令: copydb 在数据库之间复制数据，migrate 查看或执行数据库迁移
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "copydb":
+			app.RunCopyDb(os.Args[2:])
+			return
+		case "migrate":
+			app.RunMigrate(os.Args[2:])
+			return
+		}
+	}
 	app.RunHttp()
 }
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot proc 文件系统的挂载点
+const procRoot = "/proc"
+
+// ListenerInfo 正在监听的套接字及其所属进程
+type ListenerInfo struct {
+	Port    int64  `json:"port"`
+	Address string `json:"address"` // 监听地址，如 0.0.0.0、127.0.0.1、::
+	Family  string `json:"family"`  // tcp、tcp6、udp、udp6
+	Uid     int    `json:"uid"`     // 套接字所属用户ID
+	User    string `json:"user"`    // 套接字所属用户，无法解析时为用户ID
+	Pid     int    `json:"pid"`     // 所属进程ID，无权限读取进程的文件描述符时为 0
+	Process string `json:"process"` // 进程名称
+	Exe     string `json:"exe"`     // 可执行文件路径
+	Cmdline string `json:"cmdline"` // 命令行，参数以空格分隔
+	Inode   uint64 `json:"inode"`
+}
+
+// IsTCP 是否为 TCP 监听
+func (l ListenerInfo) IsTCP() bool {
+	return l.Family == "tcp" || l.Family == "tcp6"
+}
+
+// socketTables 读取的套接字表及其监听状态：TCP 为 LISTEN(0A)，UDP 为未连接(07)
+var socketTables = []struct {
+	family string
+	state  string
+}{
+	{"tcp", "0A"},
+	{"tcp6", "0A"},
+	{"udp", "07"},
+	{"udp6", "07"},
+}
+
+// ListListeners 读取 /proc/net/tcp、tcp6、udp、udp6 获取正在监听的套接字，并通过 /proc/<pid>/fd 查找所属进程
+func ListListeners() ([]ListenerInfo, error) {
+	return scanListeners(procRoot)
+}
+
+// scanListeners 从指定的 proc 目录读取监听的套接字
+func scanListeners(root string) ([]ListenerInfo, error) {
+	var listeners []ListenerInfo
+	for _, table := range socketTables {
+		entries, err := readSocketTable(filepath.Join(root, "net", table.family), table.family, table.state)
+		if err != nil {
+			// 未启用 IPv6 时没有 tcp6、udp6，但必须能读取 tcp
+			if os.IsNotExist(err) && table.family != "tcp" {
+				continue
+			}
+			return nil, fmt.Errorf("读取 %s 失败: %v", filepath.Join(root, "net", table.family), err)
+		}
+		listeners = append(listeners, entries...)
+	}
+
+	inodes := make(map[uint64]bool, len(listeners))
+	for _, listener := range listeners {
+		inodes[listener.Inode] = true
+	}
+	owners := socketOwners(root, inodes)
+
+	users := make(map[int]string)
+	processes := make(map[int]ListenerInfo)
+	for i := range listeners {
+		listener := &listeners[i]
+		if _, ok := users[listener.Uid]; !ok {
+			users[listener.Uid] = lookupUser(listener.Uid)
+		}
+		listener.User = users[listener.Uid]
+
+		pid, ok := owners[listener.Inode]
+		if !ok {
+			continue
+		}
+		process, ok := processes[pid]
+		if !ok {
+			process = readProcess(root, pid)
+			processes[pid] = process
+		}
+		listener.Pid = pid
+		listener.Process = process.Process
+		listener.Exe = process.Exe
+		listener.Cmdline = process.Cmdline
+	}
+	return listeners, nil
+}
+
+// readSocketTable 解析 /proc/net 下的套接字表，只返回处于 state 状态的套接字
+func readSocketTable(path, family, state string) ([]ListenerInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var listeners []ListenerInfo
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // 跳过标题行
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		address, port, err := parseSocketAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		// UDP 只保留未连接的套接字
+		if _, remotePort, err := parseSocketAddress(fields[2]); err != nil || remotePort != 0 {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[7])
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		listeners = append(listeners, ListenerInfo{
+			Port:    port,
+			Address: address,
+			Family:  family,
+			Uid:     uid,
+			Inode:   inode,
+		})
+	}
+	return listeners, scanner.Err()
+}
+
+// parseSocketAddress 解析 IP:端口 形式的十六进制地址，IP 按 32 位分组以主机字节序存储
+// 内核按字节输出每个 32 位分组在内存中的内容，分组内为本机字节序，因此使用 binary.NativeEndian 还原，
+// 端口则已转换为数值输出，直接按十六进制解析
+func parseSocketAddress(value string) (string, int64, error) {
+	hostHex, portHex, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("无效的地址: %s", value)
+	}
+	port, err := strconv.ParseInt(portHex, 16, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("无效的端口: %s", value)
+	}
+	raw, err := hex.DecodeString(hostHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("无效的地址: %s", value)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.NativeEndian.Uint32(raw[i:]))
+	}
+	return ip.String(), port, nil
+}
+
+// socketOwners 遍历 /proc/<pid>/fd 查找套接字所属的进程，多个进程共享套接字时优先取 go_service 以外的进程
+func socketOwners(root string, inodes map[uint64]bool) map[uint64]int {
+	owners := make(map[uint64]int)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return owners
+	}
+
+	self := os.Getpid()
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid <= 0 {
+			continue
+		}
+		fdDir := filepath.Join(root, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// 进程已退出或无权限读取
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil || !inodes[inode] {
+				continue
+			}
+			if owner, ok := owners[inode]; !ok || owner == self {
+				owners[inode] = pid
+			}
+		}
+	}
+	return owners
+}
+
+// readProcess 读取进程的名称、可执行文件和命令行，无权限读取的字段为空
+func readProcess(root string, pid int) ListenerInfo {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	var process ListenerInfo
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		process.Process = strings.TrimSpace(string(comm))
+	}
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		process.Exe = strings.TrimSuffix(exe, " (deleted)")
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		process.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	if process.Process == "" && process.Exe != "" {
+		process.Process = filepath.Base(process.Exe)
+	}
+	return process
+}
+
+// lookupUser 用户ID对应的用户名，无法解析时返回用户ID
+func lookupUser(uid int) string {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(uid)
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const socketTableHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+// fakeProc 在临时目录中构造 proc 文件系统：套接字表、进程的 fd 链接、comm、cmdline 和 exe
+type fakeProc struct {
+	t    *testing.T
+	root string
+}
+
+func newFakeProc(t *testing.T) *fakeProc {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	return &fakeProc{t: t, root: root}
+}
+
+// table 写入 /proc/net 下的套接字表，rows 为标题行之后的内容
+func (p *fakeProc) table(family string, rows ...string) {
+	p.t.Helper()
+	content := socketTableHeader
+	for _, row := range rows {
+		content += row + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(p.root, "net", family), []byte(content), 0644); err != nil {
+		p.t.Fatalf("写入 %s 失败: %v", family, err)
+	}
+}
+
+// process 创建进程目录，fd 中为指向各 inode 的套接字链接
+func (p *fakeProc) process(pid int, comm, exe, cmdline string, inodes ...uint64) {
+	p.t.Helper()
+	dir := filepath.Join(p.root, strconv.Itoa(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0755); err != nil {
+		p.t.Fatalf("创建进程目录失败: %v", err)
+	}
+	files := map[string]string{
+		"comm":    comm + "\n",
+		"cmdline": cmdline,
+		"status":  "Name:\t" + comm + "\nPid:\t" + strconv.Itoa(pid) + "\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			p.t.Fatalf("写入 %s 失败: %v", name, err)
+		}
+	}
+	links := map[string]string{"exe": exe, filepath.Join("fd", "0"): "/dev/null"}
+	for i, inode := range inodes {
+		links[filepath.Join("fd", strconv.Itoa(i+3))] = "socket:[" + strconv.FormatUint(inode, 10) + "]"
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			p.t.Fatalf("创建链接 %s 失败: %v", name, err)
+		}
+	}
+}
+
+// socketRow 套接字表中的一行
+func socketRow(local, remote, state, inode string) string {
+	return "   0: " + local + " " + remote + " " + state + " 00000000:00000000 00:00000000 00000000     0        0 " + inode + " 1 0000000000000000 100 0 0 10 0"
+}
+
+func TestScanListeners(t *testing.T) {
+	// 表中的地址为 x86 等小端机器上内核的输出
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("测试数据为小端字节序")
+	}
+
+	proc := newFakeProc(t)
+	proc.table("tcp",
+		socketRow("0100007F:1F90", "00000000:0000", "0A", "1001"), // 127.0.0.1:8080
+		socketRow("00000000:0050", "00000000:0000", "0A", "1002"), // 0.0.0.0:80
+		socketRow("0100007F:D431", "0100007F:1F90", "01", "1004"), // 已建立的连接
+		socketRow("0A00000A:0BB8", "00000000:0000", "0A", "1005"), // 10.0.0.10:3000，没有所属进程
+	)
+	proc.table("tcp6",
+		socketRow("00000000000000000000000001000000:1F91", "00000000000000000000000000000000:0000", "0A", "1003"), // [::1]:8081
+		socketRow("B80D0120000000000000000001000000:1F92", "00000000000000000000000000000000:0000", "0A", "1006"), // [2001:db8::1]:8082
+		socketRow("00000000000000000000000000000000:0016", "00000000000000000000000000000000:0000", "06", "1007"), // TIME_WAIT
+	)
+	proc.table("udp",
+		socketRow("00000000:0035", "00000000:0000", "07", "1008"), // 0.0.0.0:53
+		socketRow("0100007F:C350", "0100007F:0035", "07", "1009"), // 已连接的 UDP
+	)
+
+	self := os.Getpid()
+	proc.process(100, "api", "/usr/bin/api (deleted)", "/usr/bin/api\x00--port\x008080\x00", 1001, 1004)
+	// go_service 与子进程共享监听的套接字时取子进程
+	proc.process(self, "go_service", "/usr/bin/go_service", "go_service\x00", 1002)
+	proc.process(200, "nginx", "/usr/sbin/nginx", "nginx: master process\x00", 1002, 1003)
+	proc.process(300, "", "/usr/bin/dns", "dns\x00", 1006, 1008)
+
+	listeners, err := scanListeners(proc.root)
+	if err != nil {
+		t.Fatalf("读取监听的套接字失败: %v", err)
+	}
+
+	tests := []struct {
+		port    int64
+		family  string
+		address string
+		pid     int
+		process string
+		exe     string
+		cmdline string
+	}{
+		{8080, "tcp", "127.0.0.1", 100, "api", "/usr/bin/api", "/usr/bin/api --port 8080"},
+		{80, "tcp", "0.0.0.0", 200, "nginx", "/usr/sbin/nginx", "nginx: master process"},
+		{3000, "tcp", "10.0.0.10", 0, "", "", ""},
+		{8081, "tcp6", "::1", 200, "nginx", "/usr/sbin/nginx", "nginx: master process"},
+		{8082, "tcp6", "2001:db8::1", 300, "dns", "/usr/bin/dns", "dns"},
+		{53, "udp", "0.0.0.0", 300, "dns", "/usr/bin/dns", "dns"},
+	}
+	if len(listeners) != len(tests) {
+		t.Fatalf("返回 %d 个监听，期望 %d: %+v", len(listeners), len(tests), listeners)
+	}
+	byPort := make(map[int64]ListenerInfo, len(listeners))
+	for _, listener := range listeners {
+		byPort[listener.Port] = listener
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatInt(tt.port, 10), func(t *testing.T) {
+			listener, ok := byPort[tt.port]
+			if !ok {
+				t.Fatalf("缺少端口 %d 的监听", tt.port)
+			}
+			if listener.Family != tt.family || listener.Address != tt.address {
+				t.Fatalf("地址为 %s %s，期望 %s %s", listener.Family, listener.Address, tt.family, tt.address)
+			}
+			if listener.Pid != tt.pid || listener.Process != tt.process || listener.Exe != tt.exe || listener.Cmdline != tt.cmdline {
+				t.Fatalf("进程为 %d %q %q %q，期望 %d %q %q %q", listener.Pid, listener.Process, listener.Exe, listener.Cmdline, tt.pid, tt.process, tt.exe, tt.cmdline)
+			}
+			if listener.User == "" {
+				t.Fatalf("缺少用户")
+			}
+		})
+	}
+}
+
+func TestScanListenersWithoutIPv6(t *testing.T) {
+	proc := newFakeProc(t)
+	proc.table("tcp", socketRow("00000000:1F90", "00000000:0000", "0A", "1001"))
+
+	listeners, err := scanListeners(proc.root)
+	if err != nil {
+		t.Fatalf("没有 tcp6、udp 时读取失败: %v", err)
+	}
+	if len(listeners) != 1 || listeners[0].Port != 8080 || listeners[0].Pid != 0 {
+		t.Fatalf("返回 %+v，期望一个没有所属进程的 8080 监听", listeners)
+	}
+
+	if _, err := scanListeners(t.TempDir()); err == nil {
+		t.Fatalf("没有 tcp 时应返回错误")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"go_service/app/config"
 	"net"
 	"os"
 	"os/exec"
@@ -16,7 +15,7 @@ import (
 )
 
 var (
-	portListCache     map[string]map[string]interface{}
+	portListCache     map[int64]ListenerInfo
 	portListCacheTime time.Time
 	portListMutex     sync.RWMutex
 	cacheDuration     = 1 * time.Second // 进一步优化缓存时间为1秒
@@ -72,8 +71,8 @@ func IsPortInUse(port string) (bool, error) {
 	return false, nil
 }
 
-// GetPortList 获取正在监听的端口列表 - 带缓存优化
-func GetPortList() (map[string]map[string]interface{}, error) {
+// GetPortList 获取正在监听的 TCP 端口，按端口索引 - 带缓存优化
+func GetPortList() (map[int64]ListenerInfo, error) {
 	portListMutex.RLock()
 	if portListCache != nil && time.Since(portListCacheTime) < cacheDuration {
 		defer portListMutex.RUnlock()
@@ -99,58 +98,22 @@ func GetPortList() (map[string]map[string]interface{}, error) {
 	return portList, nil
 }
 
-// getPortListFromSystem 从系统获取端口列表
-func getPortListFromSystem() (map[string]map[string]interface{}, error) {
-	portList := make(map[string]map[string]interface{})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "netstat", "-nptl")
-	out, err := cmd.Output()
+// getPortListFromSystem 从 /proc 获取 TCP 监听，同一端口有多个监听时优先取能确定进程的
+func getPortListFromSystem() (map[int64]ListenerInfo, error) {
+	listeners, err := ListListeners()
 	if err != nil {
-		return portList, fmt.Errorf("执行netstat命令失败: %v", err)
-	}
-
-	lines := strings.Split(string(out), "\n")
-	if len(lines) < 3 {
-		return portList, nil
+		return nil, err
 	}
 
-	cpid := os.Getpid()
-	for _, line := range lines[2:] {
-		if !strings.Contains(line, "LISTEN") {
+	portList := make(map[int64]ListenerInfo)
+	for _, listener := range listeners {
+		if !listener.IsTCP() {
 			continue
 		}
-
-		parts := strings.Fields(line)
-		if len(parts) < 7 {
+		if current, ok := portList[listener.Port]; ok && (current.Pid > 0 || listener.Pid == 0) {
 			continue
 		}
-
-		address := parts[3]
-		portParts := strings.Split(address, ":")
-		port := portParts[len(portParts)-1]
-		if port == "" {
-			continue
-		}
-
-		var pid, processName string
-		process := strings.SplitN(parts[6], "/", 2)
-		if len(process) == 2 {
-			if IntToString(cpid) == process[0] && port != config.GlobalConfig.Server.Port {
-				pid = "0"
-				processName = "starting..."
-			} else {
-				pid = process[0]
-				processName = process[1]
-			}
-		}
-
-		portList[port] = map[string]interface{}{
-			"pid":     pid,
-			"process": processName,
-		}
+		portList[listener.Port] = listener
 	}
 
 	return portList, nil
@@ -168,20 +131,17 @@ func Kill(port string) (string, error) {
 		return "", fmt.Errorf("获取端口列表失败: %v", err)
 	}
 
-	if _, ok := portList[port]; !ok {
+	portNum, _ := strconv.ParseInt(port, 10, 64)
+	listener, ok := portList[portNum]
+	if !ok {
 		return "", errors.New("端口未被占用")
 	}
 
-	pidStr, ok := portList[port]["pid"].(string)
-	if !ok || pidStr == "" || pidStr == "0" {
+	pid := listener.Pid
+	if pid <= 0 {
 		return "", errors.New("无法获取进程ID")
 	}
-
-	// 验证PID是否为数字
-	pid, err := strconv.Atoi(pidStr)
-	if err != nil || pid <= 0 {
-		return "", errors.New("无效的进程ID")
-	}
+	pidStr := strconv.Itoa(pid)
 
 	// 检查是否为系统关键进程
 	if pid == 1 || pid == os.Getpid() {
@@ -210,7 +170,7 @@ func Kill(port string) (string, error) {
 	}
 
 	// 清除缓存
-	clearPortListCache()
+	ClearPortListCache()
 	return string(out), nil
 }
 
@@ -238,15 +198,15 @@ func ValidatePort(port int64) error {
 	return nil
 }
 
-// GetProcessByPort 根据端口获取进程信息
-func GetProcessByPort(port string) (map[string]interface{}, error) {
+// GetProcessByPort 根据端口获取监听的进程信息
+func GetProcessByPort(port int64) (*ListenerInfo, error) {
 	portList, err := GetPortList()
 	if err != nil {
 		return nil, err
 	}
 
 	if info, ok := portList[port]; ok {
-		return info, nil
+		return &info, nil
 	}
 
 	return nil, errors.New("端口未被占用")
@@ -266,8 +226,8 @@ func validatePortString(port string) error {
 	return ValidatePort(int64(portNum))
 }
 
-// clearPortListCache 清除端口列表缓存
-func clearPortListCache() {
+// ClearPortListCache 清除端口列表缓存，进程状态变化后调用
+func ClearPortListCache() {
 	portListMutex.Lock()
 	defer portListMutex.Unlock()
 	portListCache = nil